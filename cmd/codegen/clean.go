@@ -0,0 +1,49 @@
+package main
+
+import (
+	"flag"
+	"time"
+
+	"github.com/maxzaleski/codegen/pkg/gen"
+)
+
+var cleanCmd = &command{
+	name:  "clean",
+	short: "remove the files produced by the jobs",
+	long: `Removes the files owned by the generator, i.e. those produced by jobs defined with 'override' or 'override-on'.
+Files meant to be edited once created are kept unless -all is set.
+
+With -plan, reports the files to be removed without removing them.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f, all, plan := &commonFlags{}, false, false
+		f.register(fs)
+		fs.BoolVar(&all, "all", false, "also remove the files meant to be edited once created")
+		fs.BoolVar(&plan, "plan", false, "report the files to be removed without removing them")
+
+		return func() int {
+			return clean(f, gen.Config{
+				DebugMode:      f.debug,
+				DebugVerbose:   f.debugVerbose,
+				DisableLogFile: f.disableLogFile,
+				Location:       f.location,
+				Clean:          true,
+				CleanAll:       all,
+				Plan:           plan,
+				WorkerCount:    30,
+			})
+		}
+	},
+}
+
+func clean(f *commonFlags, c gen.Config) int {
+	start := time.Now()
+
+	res, err := gen.Execute(c, start)
+	o := f.output(*res.Metadata, start)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	o.PrintCleanReport(res.Metrics, c.Plan)
+	return exitOK
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"flag"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/slog"
+	"github.com/maxzaleski/codegen/pkg/gen"
+	"github.com/maxzaleski/codegen/pkg/output"
+	"github.com/pkg/errors"
+)
+
+type (
+	// commonFlags represents the flags shared by all commands operating on an existing '.codegen' directory.
+	commonFlags struct {
+		location       string
+		debug          bool
+		debugVerbose   bool
+		disableLogFile bool
+	}
+
+	// genFlags represents the flags shared by all commands executing the generation.
+	genFlags struct {
+		commonFlags
+
+		workers         int
+		workerMetrics   bool
+		deleteTmp       bool
+		ignoreTemplates bool
+		plan            bool
+		diff            bool
+		patch           string
+		vars            varsFlag
+	}
+
+	// varsFlag represents the variables set via the command line (e.g. '-var module=example.com/app'); the flag may be
+	// repeated.
+	varsFlag map[string]string
+)
+
+func (v *varsFlag) String() string {
+	pairs := make([]string, 0, len(*v))
+	for k, val := range *v {
+		pairs = append(pairs, k+"="+val)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (v *varsFlag) Set(s string) error {
+	k, val, ok := strings.Cut(s, "=")
+	if !ok || k == "" {
+		return errors.Errorf("invalid variable '%s'; expected 'key=value'", s)
+	}
+	if *v == nil {
+		*v = make(varsFlag)
+	}
+	(*v)[k] = val
+	return nil
+}
+
+func (f *commonFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.location, "location", "", "specify location of the tool's folder; default: '{cwd}/.codegen'")
+	fs.BoolVar(&f.debug, "debug", false, "enable debug mode; prints debug messages to stdout")
+	fs.BoolVar(&f.debugVerbose, "debugVerbose", false, "enable debug verbose mode; prints verbose error messages to stdout")
+	fs.BoolVar(&f.disableLogFile, "disableLogFile", false, "disable the error log file at '{cwd}/codegen_error.log'")
+}
+
+// spec parses the '.codegen' directory; the returned metadata is always usable.
+func (f *commonFlags) spec(began time.Time) (*core.Spec, core.Metadata, error) {
+	spec, err := core.NewSpec(slog.New(f.debug, began), f.location)
+	md := core.Metadata{}
+	if spec != nil {
+		md = *spec.Metadata
+	}
+	return spec, md, err
+}
+
+func (f *commonFlags) output(md core.Metadata, began time.Time) output.Client {
+	return output.New(md, began, f.disableLogFile, f.debugVerbose)
+}
+
+func (f *genFlags) register(fs *flag.FlagSet) {
+	f.commonFlags.register(fs)
+	fs.IntVar(&f.workers, "workers", 30, "specify number of workers available in the runtime concierge")
+	fs.BoolVar(&f.workerMetrics, "workerMetrics", false, "debug must be enabled; prints worker metrics to stdout")
+	fs.BoolVar(&f.deleteTmp, "deleteTmp", false, "deletes the dir structure at '{cwd}/tmp'")
+	fs.BoolVar(&f.ignoreTemplates, "ignoreTemplates", false, "ignore templates read from configuration")
+	fs.BoolVar(&f.plan, "plan", false, "report what each job would do without writing to disk")
+	fs.BoolVar(&f.diff, "diff", false, "print a unified diff of each overwritten file")
+	fs.StringVar(&f.patch, "patch", "", "write the unified diff of each overwritten file to the given patch file; implies -diff")
+	fs.Var(&f.vars, "var", "set a variable as 'key=value', overriding the 'vars' of the configuration; may be repeated")
+}
+
+func (f *genFlags) config() gen.Config {
+	return gen.Config{
+		DebugMode:          f.debug,
+		DebugVerbose:       f.debugVerbose,
+		DebugWorkerMetrics: f.workerMetrics,
+		DeleteTmp:          f.deleteTmp,
+		IgnoreTemplates:    f.ignoreTemplates,
+		DisableLogFile:     f.disableLogFile,
+		Diff:               f.diff || f.patch != "",
+		Location:           f.location,
+		Plan:               f.plan,
+		WorkerCount:        f.workers,
+		Vars:               f.vars,
+	}
+}
+
+// report prints the outcome of a successful execution, and returns the number of files out of date (check mode).
+func (f *genFlags) report(o output.Client, c gen.Config, res *gen.Result) (drifted int, err error) {
+	if c.Check {
+		drifted = o.PrintCheckReport(res.Metrics)
+	} else if c.Plan {
+		o.PrintPlanReport(res.Metrics)
+	} else {
+		o.PrintFinalReport(res.Metrics)
+	}
+	if c.Diff {
+		o.PrintDiffReport(res.Metrics)
+	}
+	if f.patch != "" {
+		err = o.WritePatch(res.Metrics, f.patch)
+	}
+	return
+}
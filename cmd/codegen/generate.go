@@ -0,0 +1,116 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/slog"
+	"github.com/maxzaleski/codegen/pkg/gen"
+	"github.com/maxzaleski/codegen/pkg/vfs"
+	"github.com/pkg/errors"
+)
+
+var generateCmd = &command{
+	name:  "generate",
+	short: "execute the jobs defined in the '.codegen' directory",
+	long: `Executes the jobs defined in '.codegen/config.yaml' for each package defined in '.codegen/pkg'.
+
+With -plan, reports what each job would do without writing to disk. With -check, verifies that the generated
+files are up to date without writing to disk: files regenerated on every run ('override', 'override-on') must match
+their rendered content byte for byte, whereas files created once, which are yours to edit, must only exist. Files
+generated by the last run that no jobs produce anymore are reported as orphaned; the last run is recorded locally
+('.codegen/.run'), hence orphans are not detected in a fresh checkout. With -archive, writes all generated files to a
+single archive; files on disk are neither read nor written.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f, check, archive := &genFlags{}, false, ""
+		f.register(fs)
+		fs.BoolVar(&check, "check", false, "verify that generated files are up to date without writing to disk: "+
+			"files regenerated on every run must match their rendered content, files created once must exist")
+		fs.StringVar(&archive, "archive", "",
+			"write the generated files to the given archive (.tar, .tar.gz, .tgz, .zip) rather than to disk")
+
+		return func() int {
+			c := f.config()
+			c.Check = check
+			if archive == "" {
+				return generate(f, c)
+			}
+			if _, ok := vfs.ArchiveFormatOf(archive); !ok {
+				_, _ = fmt.Fprintf(fs.Output(), "codegen generate: unknown archive format '%s'\n\n", archive)
+				fs.Usage()
+				return exitUsage
+			}
+			return generateArchive(f, c, archive)
+		}
+	},
+}
+
+func generate(f *genFlags, c gen.Config) int {
+	start := time.Now()
+
+	// Execute code generation.
+	res, err := gen.Execute(c, start)
+
+	// Instantiate output client.
+	o := f.output(*res.Metadata, start)
+
+	// Handle outcome.
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	drifted, err := f.report(o, c, res)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	if drifted != 0 {
+		return exitDrift
+	}
+	return exitOK
+}
+
+// generateArchive executes the code generation, writing the generated files to an archive at `dest`.
+func generateArchive(f *genFlags, c gen.Config, dest string) int {
+	start := time.Now()
+	o := f.output(core.Metadata{}, start)
+
+	root, err := os.Getwd()
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	if f.location != "" {
+		root += "/" + f.location
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		o.PrintError(errors.Wrapf(err, "failed to create archive at '%s'", dest))
+		return exitFailure
+	}
+	format, _ := vfs.ArchiveFormatOf(dest)
+	a := vfs.NewArchive(file, format, root)
+	c.FS = a
+
+	code := generate(f, c)
+	if code == exitOK {
+		if err = a.Close(); err != nil {
+			o.PrintError(err)
+			code = exitFailure
+		}
+	}
+	if err = file.Close(); err != nil && code == exitOK {
+		o.PrintError(errors.Wrapf(err, "failed to close archive at '%s'", dest))
+		code = exitFailure
+	}
+	if code != exitOK {
+		_ = os.Remove(dest)
+		return code
+	}
+	o.PrintInfo(fmt.Sprintf("Archive written to %s.", slog.Atom(slog.Cyan, dest)))
+	return exitOK
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/fs"
+	"github.com/maxzaleski/codegen/internal/importer"
+	"github.com/pkg/errors"
+)
+
+// importFlags represents the flags of the 'import' command.
+type importFlags struct {
+	commonFlags
+
+	src, out, name string
+	force          bool
+	goOpts         importer.GoOptions
+}
+
+var importCmd = &command{
+	name:  "import",
+	short: "create a package specification from existing sources",
+	long: `Creates a package specification from the sources located at -src:
+
+• a Go package (directory): structs are imported as models (fields as properties, methods as functions), and an
+  interface as the package's interface. Doc comments are imported as descriptions, and struct tags as the 'tags'
+  addon of properties.
+• an OpenAPI 3 or JSON Schema document (YAML or JSON file): object schemas are imported as models, and operations as
+  the functions of the package's interface (parameters and request body as parameters, success response as return
+  parameter).
+
+The specification is written to '{location}/.codegen/pkg/{name}.yaml', unless -out is set ('-' for stdout). Fails if
+the file already exists, unless -force is set.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f := &importFlags{}
+		f.commonFlags.register(fs)
+		fs.StringVar(&f.src, "src", "", "location of the Go package, or OpenAPI/JSON Schema document to import (required)")
+		fs.StringVar(&f.out, "out", "", "destination of the specification; '-' for stdout")
+		fs.BoolVar(&f.force, "force", false, "overwrite an existing specification")
+		fs.StringVar(&f.name, "name", "", "name of the package; default: the name of the Go package, or of the document")
+		fs.StringVar(&f.goOpts.Interface, "interface", "", "Go only; interface imported as the package's interface; default: the first one declared")
+		fs.BoolVar(&f.goOpts.Unexported, "unexported", false, "Go only; import unexported declarations")
+
+		return func() int {
+			if f.src == "" {
+				_, _ = fmt.Fprint(fs.Output(), "codegen import: -src is required\n\n")
+				fs.Usage()
+				return exitUsage
+			}
+			return importPkg(f)
+		}
+	},
+}
+
+func importPkg(f *importFlags) int {
+	start := time.Now()
+
+	cwd, err := os.Getwd()
+	o := f.output(core.Metadata{Cwd: cwd}, start)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+
+	var res *importer.Result
+	if info, statErr := os.Stat(f.src); statErr == nil && info.IsDir() {
+		f.goOpts.Name = f.name
+		res, err = importer.FromGo(f.src, f.goOpts)
+	} else {
+		res, err = importer.FromOpenAPI(f.src, importer.OpenAPIOptions{Name: f.name})
+	}
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	b, err := importer.Marshal(res.Package)
+	if err != nil {
+		o.PrintError(errors.Wrap(err, "failed to encode package specification"))
+		return exitFailure
+	}
+
+	if f.out == "-" {
+		fmt.Print(string(b))
+		return exitOK
+	}
+	dest := f.out
+	if dest == "" {
+		dest = filepath.Join(f.location, core.DomainDir, "pkg", res.Package.Name+".yaml")
+	}
+	if !f.force && fs.FileExists(dest) {
+		o.PrintError(errors.Errorf("refusing to overwrite existing file at '%s'", dest))
+		return exitFailure
+	}
+	if _, err = fs.CreateDirINE(filepath.Dir(dest)); err == nil {
+		err = errors.Wrapf(os.WriteFile(dest, b, 0644), "failed to write file at '%s'", dest)
+	}
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+
+	lines := []string{fmt.Sprintf("Imported package '%s' to '%s'.", res.Package.Name, dest)}
+	for _, s := range res.Skipped {
+		lines = append(lines, fmt.Sprintf("Skipped '%s': not representable by the specification.", s))
+	}
+	o.PrintInfo(lines...)
+	return exitOK
+}
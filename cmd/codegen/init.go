@@ -0,0 +1,64 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/lib/slice"
+	"github.com/maxzaleski/codegen/internal/scaffold"
+)
+
+var initCmd = &command{
+	name:  "init",
+	short: "create a working '.codegen' directory",
+	long: `Creates a '.codegen' directory containing a commented 'config.yaml' defining example 'pkg' and 'http' scopes,
+a sample package ('pkg/user.yaml'), and the starter templates of the selected preset.
+
+Fails if any of the files already exists, unless -force is set.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f, preset, force := &commonFlags{}, "", false
+		presets := slice.Map(scaffold.Presets(), func(p scaffold.Preset) string { return string(p) })
+		fs.StringVar(&f.location, "location", "", "specify location of the tool's folder; default: '{cwd}/.codegen'")
+		fs.StringVar(&preset, "preset", string(scaffold.PresetGo),
+			fmt.Sprintf("language of the starter templates; one of: %s", strings.Join(presets, ", ")))
+		fs.BoolVar(&force, "force", false, "overwrite existing files")
+
+		return func() int {
+			if !scaffold.Preset(preset).IsValid() {
+				_, _ = fmt.Fprintf(fs.Output(), "codegen init: unknown preset '%s'\n\n", preset)
+				fs.Usage()
+				return exitUsage
+			}
+			return initDir(f, scaffold.Preset(preset), force)
+		}
+	},
+}
+
+func initDir(f *commonFlags, p scaffold.Preset, force bool) int {
+	start := time.Now()
+
+	cwd, err := os.Getwd()
+	var paths []string
+	if err == nil {
+		paths, err = scaffold.Write(cwd, f.location, p, force)
+	}
+
+	o := f.output(core.Metadata{Cwd: cwd}, start)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	for _, path := range paths {
+		fmt.Printf("%s\n", path)
+	}
+	o.PrintInfo(
+		fmt.Sprintf("Created '%s' (preset: %s); define your scopes in 'config.yaml', and your packages in 'pkg'.",
+			core.DomainDir, p),
+		"Run 'codegen list' to review the configuration, then 'codegen generate'.",
+	)
+	return exitOK
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+var listCmd = &command{
+	name:  "list",
+	short: "print the scopes, jobs and packages defined in the '.codegen' directory",
+	long:  `Prints the scopes defined in '.codegen/config.yaml' alongside their jobs, and the packages defined in '.codegen/pkg'.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f := &commonFlags{}
+		f.register(fs)
+
+		return func() int { return list(f) }
+	},
+}
+
+func list(f *commonFlags) int {
+	start := time.Now()
+
+	spec, md, err := f.spec(start)
+	o := f.output(md, start)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	o.PrintSpec(spec)
+	return exitOK
+}
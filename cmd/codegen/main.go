@@ -2,65 +2,135 @@ package main
 
 import (
 	"flag"
-	"github.com/maxzaleski/codegen/pkg/gen"
-	"github.com/maxzaleski/codegen/pkg/output"
+	"fmt"
+	"io"
+	"log"
 	"os"
-	"text/template"
-	"time"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
 )
 
-var (
-	locFlag                = flag.String("location", "", "specify location of the tool's folder; default: '{cwd}/.codegen'")
-	debugFlag              = flag.Bool("debug", false, "enable debug mode; prints debug messages to stdout")
-	debugVerboseFlag       = flag.Bool("debugVerbose", false, "enable debug verbose mode; prints verbose error messages to stdout")
-	workersFlag            = flag.Int("workers", 30, "specify number of workers available in the runtime concierge")
-	debugWorkerMetricsFlag = flag.Bool("workerMetrics", false, "debug must be enabled; prints worker metrics.go to stdout")
-	deleteTmpFlag          = flag.Bool("deleteTmp", false, "deletes the dir structure at '{cwd}/tmp'")
-	ignoreTemplatesFlag    = flag.Bool("ignoreTemplates", false, "ignore templates read from configuration")
-	disableLogFileFlag     = flag.Bool("disableLogFile", false, "ignore templates read from configuration")
+// Exit codes shared by all commands.
+const (
+	exitOK = 0
+	// exitFailure denotes an error encountered during execution (e.g. invalid specification, template error).
+	exitFailure = 1
+	// exitUsage denotes an unknown command, or invalid flags or arguments.
+	exitUsage = 2
+	// exitDrift denotes generated files that are out of date (check mode).
+	exitDrift = 3
 )
 
-func init() {
-	flag.Parse()
+type command struct {
+	name string
+	// Represents the one-line description printed alongside the list of commands.
+	short string
+	// Represents the description printed in the command's help text.
+	long string
+	// setup registers the command's flags, and returns the function executing the command once they are parsed.
+	setup func(fs *flag.FlagSet) func() int
 }
 
-func main() { New(nil) }
+// commands lists the available commands in the order they are printed.
+var commands = []*command{
+	generateCmd,
+	watchCmd,
+	validateCmd,
+	listCmd,
+	initCmd,
+	importCmd,
+	cleanCmd,
+}
 
-// New instantiates the code generation process.
-//
-// funcMap is a map of functions that can be used in templates.
+func main() {
+	log.SetFlags(0) // Disable datetime prefix; commands other than 'generate' do not set up a logger.
+	os.Exit(run(os.Args[1:]))
+}
+
+// run executes the command designated by `args`, and returns the exit code.
 //
-//	funcMap := template.FuncMap{
-//		"add": func(a, b int) int {
-//			return a + b
-//		},
-//	}
-func New(funcMap template.FuncMap) {
-	start := time.Now()
+// Without a command, 'generate' is implied (e.g. `codegen -plan`).
+func run(args []string) int {
+	name := generateCmd.name
+	if len(args) != 0 {
+		switch a := args[0]; {
+		case a == "-h", a == "-help", a == "--help":
+			printUsage(os.Stdout)
+			return exitOK
+		case a == "help":
+			if len(args) == 1 {
+				printUsage(os.Stdout)
+				return exitOK
+			}
+			name, args = args[1], []string{"-h"}
+		case !strings.HasPrefix(a, "-"):
+			name, args = a, args[1:]
+		}
+	}
 
-	// Execute code generation.
-	c := gen.Config{
-		DebugMode:          *debugFlag,
-		DebugVerbose:       *debugVerboseFlag,
-		DebugWorkerMetrics: *debugWorkerMetricsFlag,
-		DeleteTmp:          *deleteTmpFlag,
-		IgnoreTemplates:    *ignoreTemplatesFlag,
-		DisableLogFile:     *disableLogFileFlag,
-		Location:           *locFlag,
-		WorkerCount:        *workersFlag,
+	cmd := findCommand(name)
+	if cmd == nil {
+		_, _ = fmt.Fprintf(os.Stderr, "codegen: unknown command '%s'\n\n", name)
+		printUsage(os.Stderr)
+		return exitUsage
+	}
 
-		TemplateFuncMap: funcMap,
+	fs := flag.NewFlagSet("codegen "+cmd.name, flag.ContinueOnError)
+	fs.Usage = func() { printCommandUsage(fs, cmd) }
+	exec := cmd.setup(fs)
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK
+		}
+		return exitUsage
 	}
-	res, err := gen.Execute(c, start)
+	if fs.NArg() != 0 {
+		_, _ = fmt.Fprintf(fs.Output(), "codegen %s: unexpected arguments: %s\n\n", cmd.name, strings.Join(fs.Args(), " "))
+		fs.Usage()
+		return exitUsage
+	}
+	return exec()
+}
 
-	// Instantiate output client.
-	o := output.New(*res.Metadata, start, c.DisableLogFile, c.DebugVerbose)
+func findCommand(name string) *command {
+	for _, c := range commands {
+		if c.name == name {
+			return c
+		}
+	}
+	return nil
+}
 
-	// Handle outcome.
-	if err != nil {
-		o.PrintError(err)
-		os.Exit(1)
-	} else {
-		o.PrintFinalReport(res.Metrics)
+func printUsage(w io.Writer) {
+	_, _ = fmt.Fprint(w, "Code generation tool based on templates and user-defined configuration files.\n\n")
+	_, _ = fmt.Fprint(w, "Usage:\n  codegen <command> [flags]\n\nCommands:\n")
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, c := range commands {
+		_, _ = fmt.Fprintf(tw, "  %s\t%s\n", c.name, c.short)
 	}
+	_ = tw.Flush()
+
+	_, _ = fmt.Fprintf(w, "\nRun 'codegen help <command>' for the flags of a command; without a command, '%s' is implied.\n",
+		generateCmd.name)
+	printExitCodes(w)
+}
+
+func printCommandUsage(fs *flag.FlagSet, c *command) {
+	w := fs.Output()
+	_, _ = fmt.Fprintf(w, "Usage:\n  codegen %s [flags]\n\n%s\n\nFlags:\n", c.name, c.long)
+	fs.PrintDefaults()
+	printExitCodes(w)
+}
+
+func printExitCodes(w io.Writer) {
+	_, _ = fmt.Fprintf(w, `
+Exit codes:
+  %d  success
+  %d  failure (e.g. invalid specification, template error)
+  %d  invalid usage (unknown command, invalid flags or arguments)
+  %d  generated files are out of date (generate -check)
+`, exitOK, exitFailure, exitUsage, exitDrift)
 }
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"flag"
+	"time"
+)
+
+var validateCmd = &command{
+	name:  "validate",
+	short: "parse and validate the '.codegen' directory without generating",
+	long:  `Parses '.codegen/config.yaml' and the packages defined in '.codegen/pkg', and reports any validation error.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f := &commonFlags{}
+		f.register(fs)
+
+		return func() int { return validate(f) }
+	},
+}
+
+func validate(f *commonFlags) int {
+	start := time.Now()
+
+	spec, md, err := f.spec(start)
+	o := f.output(md, start)
+	if err != nil {
+		o.PrintError(err)
+		return exitFailure
+	}
+	o.PrintValidReport(spec)
+	return exitOK
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/maxzaleski/codegen/pkg/gen"
+)
+
+var watchCmd = &command{
+	name:  "watch",
+	short: "execute the jobs, then re-execute the affected ones upon change",
+	long: `Executes the jobs defined in '.codegen/config.yaml', then watches '.codegen/config.yaml', '.codegen/pkg'
+and the templates referenced by the configuration. Upon change, only the affected jobs are re-executed.
+
+Press Ctrl+C to exit.`,
+	setup: func(fs *flag.FlagSet) func() int {
+		f, wc := &genFlags{}, gen.WatchConfig{}
+		f.register(fs)
+		fs.DurationVar(&wc.Interval, "interval", 500*time.Millisecond, "interval at which files are polled")
+		fs.DurationVar(&wc.Debounce, "debounce", 300*time.Millisecond, "period without change to observe before regenerating")
+
+		return func() int { return watch(f, wc) }
+	},
+}
+
+func watch(f *genFlags, wc gen.WatchConfig) int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	c := f.config()
+	err := gen.Watch(ctx, c, wc, func(res *gen.Result, err error) {
+		o := f.output(*res.Metadata, res.Began)
+		if err == nil {
+			_, err = f.report(o, c, res)
+		}
+		if err != nil {
+			o.PrintError(err)
+		}
+		o.PrintInfo("Watching for changes; press Ctrl+C to exit.")
+	})
+	if err != nil {
+		return exitFailure
+	}
+	return exitOK
+}
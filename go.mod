@@ -3,10 +3,12 @@ module github.com/maxzaleski/codegen
 go 1.19
 
 require (
+	github.com/BurntSushi/toml v1.3.2
 	github.com/go-playground/assert v1.2.1
 	github.com/go-playground/validator/v10 v10.14.0
 	github.com/iancoleman/strcase v0.2.0
 	github.com/mattn/go-sqlite3 v1.14.17
+	github.com/mitchellh/hashstructure/v2 v2.0.2
 	github.com/pkg/errors v0.9.1
 	golang.org/x/sync v0.3.0
 	gopkg.in/yaml.v3 v3.0.1
@@ -17,7 +19,6 @@ require (
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
 	github.com/leodido/go-urn v1.2.4 // indirect
-	github.com/mitchellh/hashstructure/v2 v2.0.2 // indirect
 	golang.org/x/crypto v0.7.0 // indirect
 	golang.org/x/net v0.8.0 // indirect
 	golang.org/x/sys v0.6.0 // indirect
@@ -1,16 +1,26 @@
 package core
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/BurntSushi/toml"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/pkg/errors"
 	"gopkg.in/yaml.v3"
+	"io"
 	"os"
 	"path/filepath"
 )
 
 const (
-	DomainDir   = ".codegen"
-	domainEntry = "config.yaml"
+	DomainDir = ".codegen"
+	// TemplatesDir represents the templates library, relative to `DomainDir`; templates within its 'partials'
+	// directory are included in every job.
+	TemplatesDir = "templates"
+	// TemplatesPartialsDir represents the partials of the templates library, relative to `TemplatesDir`.
+	TemplatesPartialsDir = "partials"
+	domainEntry          = "config.yaml"
 
 	event = "parsing"
 )
@@ -56,40 +66,62 @@ func NewSpec(rl slog.ILogger, src string) (spec *Spec, err error) {
 		return
 	}
 
-	// Parse all packages.
-	//
-	// /!\ Assumes a flat directory structure.
-	err = filepath.Walk(cdp+"/pkg", func(path string, info os.FileInfo, err error) error {
+	// Parse all packages; nested directories qualify the path of their packages (e.g. 'billing/invoice').
+	pkgDir, pkgFilesMap := cdp+"/pkg", make(map[string]string)
+	err = filepath.Walk(pkgDir, func(path string, info os.FileInfo, err error) error {
 		// Handle unexpected error.
 		if err != nil {
 			return errors.Wrapf(err, "unexpected error during dir walk at file '%s'", path)
 		}
-		// Skip non-YAML files.
-		if filepath.Ext(path) != ".yaml" {
+		// Skip unsupported files.
+		if !pkgExts[filepath.Ext(path)] {
 			return nil
 		}
 
 		l.Log(event, "msg", "parsing package", "path", path)
 
-		pkg := &Package{}
-		if err = unmarshal(path, pkg, false); err != nil {
+		pkgs, err := unmarshalPkgs(path)
+		if err != nil {
 			return err
 		}
-		// Primary method arguments by `index` field.
-		if len(pkg.Models) != 0 {
+		rel, _ := filepath.Rel(cdp, path)
+		for i, pkg := range pkgs {
+			// -> Designates the package's definition within error messages.
+			src := rel
+			if len(pkgs) > 1 {
+				src = fmt.Sprintf("%s (document %d)", rel, i+1)
+			}
+
+			if pkg.Name == "" {
+				return errors.Errorf("package at '%s' does not define a name", src)
+			}
+			if pkg.Path, err = qualifyPkgPath(pkgDir, path, pkg.Name); err != nil {
+				return err
+			}
+			// -> Packages are identified by their path; it must be unique.
+			if prev, ok := pkgFilesMap[pkg.Path]; ok {
+				return errors.Errorf("package '%s' is defined twice: '%s' and '%s'", pkg.Path, prev, src)
+			}
+			pkgFilesMap[pkg.Path] = src
+
+			// Sort method arguments by `index` field.
 			for _, m := range pkg.Models {
-				if len(m.Methods) != 0 {
-					for _, m := range m.Methods {
-						m.SortParams()
-					}
+				for _, m := range m.Methods {
+					m.SortParams()
 				}
 			}
-			for _, m := range pkg.Interface.Methods {
-				m.SortParams()
+			if pkg.Interface != nil {
+				for _, m := range pkg.Interface.Methods {
+					m.SortParams()
+				}
+			}
+			normaliseEndpoints(pkg)
+			if err = validatePkg(pkg); err != nil {
+				return errors.Wrapf(err, "invalid package at '%s'", src)
 			}
+			spec.Pkgs = append(spec.Pkgs, pkg)
+			spec.Metadata.PkgsLastModifiedMap[pkg.Path] = info.ModTime().UnixNano()
 		}
-		spec.Pkgs = append(spec.Pkgs, pkg)
-		spec.Metadata.PkgsLastModifiedMap[pkg.Name] = info.ModTime().UnixNano()
 
 		return nil
 	})
@@ -99,20 +131,127 @@ func NewSpec(rl slog.ILogger, src string) (spec *Spec, err error) {
 	}
 
 	// AssignMod domain types.
-	for _, s := range spec.Config.PkgDomain.Scopes {
-		s.ParentType = DomainTypePkg
+	if d := spec.Config.PkgDomain; d != nil {
+		for _, s := range d.Scopes {
+			s.ParentType = DomainTypePkg
+		}
 	}
-	for _, s := range spec.Config.HttpDomain.Scopes {
-		s.ParentType = DomainTypeHttp
+	if d := spec.Config.HttpDomain; d != nil {
+		for _, s := range d.Scopes {
+			s.ParentType = DomainTypeHttp
+		}
 	}
 
 	// Validate the resulting struct.
 	l.Log("validation", "msg", "validating configuration")
-	err = validate.Struct(spec.Config)
+	if err = validateTokens(spec.Config); err != nil {
+		return
+	}
+	if err = validate.Struct(spec.Config); err != nil {
+		return
+	}
+
+	// Interpolate the environment variables referred to by user-defined variables.
+	if err = spec.Config.interpolateVars(); err != nil {
+		err = errors.Wrap(err, "failed to interpolate variables")
+		return
+	}
+
+	// Resolve the types referred to by the packages.
+	l.Log("resolution", "msg", "resolving type references")
+	err = spec.resolve()
 
 	return
 }
 
+// qualifyPkgPath returns the path of the package defined at `path`: its directory relative to `pkgDir`, joined with
+// its name (e.g. '.codegen/pkg/billing/invoice.yaml' => 'billing/invoice').
+func qualifyPkgPath(pkgDir, path, name string) (string, error) {
+	dir, err := filepath.Rel(pkgDir, filepath.Dir(path))
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to qualify package at '%s'", path)
+	}
+	if dir == "." {
+		return name, nil
+	}
+	return filepath.ToSlash(dir) + "/" + name, nil
+}
+
+// pkgExts represents the extensions of package files.
+var pkgExts = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+	".toml": true,
+}
+
+// unmarshalPkgs parses the package file at `path` as per its extension.
+//
+// YAML files may define several packages as separate documents ('---'), and JSON files as an array. JSON and TOML
+// documents are converted to YAML, as to share the same field names.
+func unmarshalPkgs(path string) ([]*Package, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	docs := make([][]byte, 0)
+	switch filepath.Ext(path) {
+	case ".json":
+		var v any
+		if err = json.Unmarshal(bs, &v); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal file at '%s'", path)
+		}
+		vs, ok := v.([]any)
+		if !ok {
+			vs = []any{v}
+		}
+		for _, v := range vs {
+			b, err := yaml.Marshal(v)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to convert file at '%s'", path)
+			}
+			docs = append(docs, b)
+		}
+	case ".toml":
+		v := make(map[string]any)
+		if err = toml.Unmarshal(bs, &v); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal file at '%s'", path)
+		}
+		b, err := yaml.Marshal(v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to convert file at '%s'", path)
+		}
+		docs = append(docs, b)
+	default:
+		docs = append(docs, bs)
+	}
+
+	pkgs := make([]*Package, 0, len(docs))
+	for _, doc := range docs {
+		dec := yaml.NewDecoder(bytes.NewReader(doc))
+		for {
+			var node yaml.Node
+			if err = dec.Decode(&node); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, errors.Wrapf(err, "failed to unmarshal file at '%s' (document %d)", path, len(pkgs)+1)
+			}
+			// -> Skip empty documents (e.g. a trailing separator).
+			if len(node.Content) == 0 || node.Content[0].Tag == "!!null" {
+				continue
+			}
+			pkg := &Package{}
+			if err = node.Decode(pkg); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal file at '%s' (document %d)", path, len(pkgs)+1)
+			}
+			pkgs = append(pkgs, pkg)
+		}
+	}
+	return pkgs, nil
+}
+
 // unmarshal wraps `yaml.Unmarshal`.
 //
 // Param: `checkPresence` determines whether to return an error if the file is not found.
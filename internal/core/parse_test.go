@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -41,6 +43,151 @@ func TestNewSpec(t *testing.T) {
 		_, err = NewSpec(l, specified)
 		assert.Equal(t, err, nil)
 	})
+
+	t.Run("nested packages", func(t *testing.T) {
+		reset, err := setupTestDir("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reset()
+
+		if err = writePkg("billing/invoice.yaml", "name: invoice"); err != nil {
+			t.Fatal(err)
+		}
+		spec, err := NewSpec(l, "")
+		assert.Equal(t, err, nil)
+
+		paths := make(map[string]bool)
+		for _, p := range spec.Pkgs {
+			paths[p.Path] = true
+		}
+		assert.Equal(t, paths, map[string]bool{"user": true, "billing/invoice": true})
+		assert.NotEqual(t, spec.Metadata.PkgsLastModifiedMap["billing/invoice"], int64(0))
+	})
+
+	t.Run("same name in different directories", func(t *testing.T) {
+		reset, err := setupTestDir("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reset()
+
+		if err = writePkg("account/user.yaml", "name: user"); err != nil {
+			t.Fatal(err)
+		}
+		spec, err := NewSpec(l, "")
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(spec.Pkgs), 2)
+	})
+
+	t.Run("duplicate packages", func(t *testing.T) {
+		reset, err := setupTestDir("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reset()
+
+		if err = writePkg("users.yaml", "name: user"); err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewSpec(l, "")
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, strings.Contains(err.Error(), "package 'user' is defined twice"), true)
+	})
+
+	t.Run("package without name", func(t *testing.T) {
+		reset, err := setupTestDir("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reset()
+
+		if err = writePkg("anonymous.yaml", "models: []"); err != nil {
+			t.Fatal(err)
+		}
+		_, err = NewSpec(l, "")
+		assert.NotEqual(t, err, nil)
+	})
+
+	t.Run("package formats", func(t *testing.T) {
+		cases := []struct {
+			name  string
+			file  string
+			data  string
+			paths []string
+		}{
+			{"yml", "order.yml", "name: order", []string{"order"}},
+			{"json object", "order.json", `{"name": "order", "models": [{"name": "Order"}]}`, []string{"order"}},
+			{"json array", "orders.json", `[{"name": "order"}, {"name": "invoice"}]`, []string{"order", "invoice"}},
+			{"toml", "order.toml", "name = \"order\"\n[[models]]\nname = \"Order\"", []string{"order"}},
+			{"multi-document yaml", "orders.yaml", "name: order\n---\nname: invoice\n---\n", []string{"order", "invoice"}},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				reset, err := setupTestDir("")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer reset()
+
+				if err = writePkg(c.file, c.data); err != nil {
+					t.Fatal(err)
+				}
+				spec, err := NewSpec(l, "")
+				assert.Equal(t, err, nil)
+
+				paths := make(map[string]bool)
+				for _, p := range spec.Pkgs {
+					paths[p.Path] = true
+				}
+				for _, p := range c.paths {
+					assert.Equal(t, paths[p], true)
+					assert.NotEqual(t, spec.Metadata.PkgsLastModifiedMap[p], int64(0))
+				}
+				assert.Equal(t, len(spec.Pkgs), len(c.paths)+1)
+			})
+		}
+	})
+
+	t.Run("invalid package file", func(t *testing.T) {
+		cases := []struct {
+			name string
+			file string
+			data string
+			want string
+		}{
+			{"json", "order.json", `{"name": `, "order.json"},
+			{"toml", "order.toml", "name = ", "order.toml"},
+			{"yaml document", "orders.yaml", "name: order\n---\nname: [", "orders.yaml' (document 2)"},
+			{"duplicate in document", "orders.yaml", "name: order\n---\nname: order", "orders.yaml (document 2)"},
+			{"invalid endpoint", "bad.yaml", "name: bad\nendpoints:\n  - {name: A, method: get, path: /a, request: B}", "bad.yaml': endpoint 'GET /a'"},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				reset, err := setupTestDir("")
+				if err != nil {
+					t.Fatal(err)
+				}
+				defer reset()
+
+				if err = writePkg(c.file, c.data); err != nil {
+					t.Fatal(err)
+				}
+				_, err = NewSpec(l, "")
+				assert.NotEqual(t, err, nil)
+				assert.Equal(t, strings.Contains(err.Error(), c.want), true)
+			})
+		}
+	})
+}
+
+// writePkg writes a package file at the given path, relative to '.codegen/pkg'.
+func writePkg(path, data string) error {
+	path = DomainDir + "/pkg/" + path
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(data), 0777)
 }
 
 func setupTestDir(subDir string) (func(), error) {
@@ -90,16 +237,19 @@ const codegenCfg = `pkg:
       output: core/pkg
       jobs:
         - key: models
-          file-name: models
-          template: embeds.service
+          file-name: models.go
+          templates:
+            - name: embeds.service
 
         - key: service
-          file-name: service
-          template: go/custom
+          file-name: service.go
+          templates:
+            - name: go/custom
 
         - key: repository
-          file-name: repository_logger
-          template: go/custom
+          file-name: repository_logger.go
+          templates:
+            - name: go/custom
 
     # Java Example
     - key: java-server-models
@@ -107,14 +257,16 @@ const codegenCfg = `pkg:
       jobs:
         - key: models
           file-name: \{pkg.asTitle\}.java
-          template: java/model
+          templates:
+            - name: java/model
 
     - key: java-server-services
       output: java/main/src/services
       jobs:
         - key: service
           file-name: \{pkg.asTitle.asCamel\}Service.java
-          template: java/service
+          templates:
+            - name: java/service
 
 
 http:
@@ -123,15 +275,17 @@ http:
       output: cmd/api/http
       jobs:
         - key: controller
-          file-name: \{pkg.asLower.asSnake\}_\{pkg.asUpper.asSnake\}controller
-          template: go/custom/controller
+          file-name: \{pkg.asLower.asSnake\}_controller.go
+          templates:
+            - name: go/custom/controller
 
     - key: flutter-client
       output: apps/flutter/lib/api
       jobs:
         - key: api
           file-name: api.dart
-          template: dart/api
+          templates:
+            - name: dart/api
           concat: true
 
     - key: nextjs-client
@@ -139,7 +293,8 @@ http:
       jobs:
         - key: controller
           file-name: api.ts
-          template: dart/api
+          templates:
+            - name: dart/api
           concat: true
 
 `
@@ -0,0 +1,429 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// BuiltinTypes represents the generic types packages may refer to, along with the number of type arguments they
+// expect (e.g. 'map<string,int>').
+var BuiltinTypes = map[string]int{
+	"any": 0, "bool": 0, "string": 0, "bytes": 0, "byte": 0, "rune": 0, "error": 0,
+	"int": 0, "int8": 0, "int16": 0, "int32": 0, "int64": 0,
+	"uint": 0, "uint8": 0, "uint16": 0, "uint32": 0, "uint64": 0,
+	"float32": 0, "float64": 0, "decimal": 0,
+	"uuid": 0, "date": 0, "datetime": 0, "duration": 0,
+	"list": 1, "set": 1, "map": 2,
+}
+
+// TypeRef represents a parsed type (e.g. `map<string,list<billing.Invoice>>?`), linked to its definition.
+//
+// The generic notation is complemented by the Go one for pointers and slices (e.g. `*User` => `User?`, `[]User` =>
+// `list<User>`), as found in specifications imported from Go sources.
+type TypeRef struct {
+	// Name of the type, without qualifier (e.g. 'Invoice').
+	Name string
+	// Qualifier represents the package qualifier, as written (e.g. 'billing'); empty if unqualified.
+	Qualifier string
+	Args      []*TypeRef
+	Optional  bool
+
+	// Represent the model or interface designated by the type, and the package defining it; nil if the type is
+	// built-in, custom (see `Config.Types`) or external (i.e. its qualifier does not designate a package).
+	Model     *Model
+	Interface *Interface
+	Package   *Package
+}
+
+// String returns the type in the generic notation.
+func (t *TypeRef) String() string {
+	var b strings.Builder
+	if t.Qualifier != "" {
+		b.WriteString(t.Qualifier + ".")
+	}
+	b.WriteString(t.Name)
+	if len(t.Args) != 0 {
+		args := make([]string, 0, len(t.Args))
+		for _, a := range t.Args {
+			args = append(args, a.String())
+		}
+		b.WriteString("<" + strings.Join(args, ",") + ">")
+	}
+	if t.Optional {
+		b.WriteString("?")
+	}
+	return b.String()
+}
+
+// IsBuiltin returns true if the type is one of `BuiltinTypes`.
+func (t *TypeRef) IsBuiltin() bool {
+	_, ok := BuiltinTypes[t.Name]
+	return ok && t.Qualifier == "" && t.Model == nil
+}
+
+// IsExternal returns true if the type is qualified by a package that is not defined by the specification (e.g.
+// 'gorm.DB').
+func (t *TypeRef) IsExternal() bool {
+	return t.Qualifier != "" && t.Package == nil
+}
+
+// Refs returns the types referring to a model or interface, including type arguments (e.g. `map<string,User>`).
+func (t *TypeRef) Refs() []*TypeRef {
+	refs := make([]*TypeRef, 0)
+	if t.Package != nil {
+		refs = append(refs, t)
+	}
+	for _, a := range t.Args {
+		refs = append(refs, a.Refs()...)
+	}
+	return refs
+}
+
+// ParseType parses a type expression.
+func ParseType(s string) (*TypeRef, error) {
+	p := &typeParser{s: s}
+	t, err := p.parse()
+	if err == nil && p.i != len(s) {
+		err = errors.Errorf("unexpected '%s' at position %d", s[p.i:], p.i)
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid type '%s'", s)
+	}
+	return t, nil
+}
+
+type typeParser struct {
+	s string
+	i int
+}
+
+func (p *typeParser) parse() (*TypeRef, error) {
+	switch {
+	case p.consume(`\*`), p.consume("*"):
+		t, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		t.Optional = true
+		return t, nil
+	case p.consume("[]"):
+		elem, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeRef{Name: "list", Args: []*TypeRef{elem}}, nil
+	case p.consume("map["):
+		k, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		if !p.consume("]") {
+			return nil, errors.Errorf("expected ']' at position %d", p.i)
+		}
+		v, err := p.parse()
+		if err != nil {
+			return nil, err
+		}
+		return &TypeRef{Name: "map", Args: []*TypeRef{k, v}}, nil
+	}
+
+	start := p.i
+	for p.i < len(p.s) && isTypeNameChar(p.s[p.i]) {
+		p.i++
+	}
+	name := p.s[start:p.i]
+	if name == "" {
+		return nil, errors.Errorf("expected a type at position %d", start)
+	}
+	t := &TypeRef{Name: name}
+	if i := strings.LastIndex(name, "."); i != -1 {
+		t.Qualifier, t.Name = name[:i], name[i+1:]
+		if t.Qualifier == "" || t.Name == "" {
+			return nil, errors.Errorf("invalid qualified name '%s' at position %d", name, start)
+		}
+	}
+
+	if p.consume("<") {
+		for {
+			a, err := p.parse()
+			if err != nil {
+				return nil, err
+			}
+			t.Args = append(t.Args, a)
+			if p.consume(",") {
+				continue
+			}
+			if p.consume(">") {
+				break
+			}
+			return nil, errors.Errorf("expected ',' or '>' at position %d", p.i)
+		}
+	}
+	if p.consume("?") {
+		t.Optional = true
+	}
+	return t, nil
+}
+
+func (p *typeParser) consume(token string) bool {
+	if strings.HasPrefix(p.s[p.i:], token) {
+		p.i += len(token)
+		return true
+	}
+	return false
+}
+
+func isTypeNameChar(c byte) bool {
+	return c == '_' || c == '.' || c == '/' || c == '-' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// resolve links the types the packages refer to (properties, parameters, `Model.Extends` and `Model.Implements`) to
+// their definition, and sets the imports of each package.
+//
+// Types are either built-in, custom (see `Config.Types`), a model or the interface of the same package (e.g. 'User'),
+// or a model or the interface of another package, qualified by its path or unique name (e.g. 'billing.Invoice');
+// qualifiers that do not designate a package are considered external (e.g. 'gorm.DB'). Unknown types, unknown
+// `Model.Implements` targets, and cycles in `Model.Extends` are reported.
+func (s *Spec) resolve() error {
+	r := &resolver{
+		spec:      s,
+		pkgsMap:   make(map[string]*Package),
+		namesMap:  make(map[string][]*Package),
+		modelsMap: make(map[*Model]*Package),
+	}
+	for _, pkg := range s.Pkgs {
+		r.pkgsMap[pkg.Path] = pkg
+		r.namesMap[pkg.Name] = append(r.namesMap[pkg.Name], pkg)
+		for i := range pkg.Models {
+			r.modelsMap[&pkg.Models[i]] = pkg
+		}
+	}
+	for _, pkg := range s.Pkgs {
+		r.pkg(pkg)
+	}
+	r.cycles()
+
+	if len(r.errs) != 0 {
+		return errors.Errorf("failed to resolve types:\n• %s", strings.Join(r.errs, "\n• "))
+	}
+	return nil
+}
+
+type resolver struct {
+	spec *Spec
+	// Represents the packages keyed by path, and by name.
+	pkgsMap  map[string]*Package
+	namesMap map[string][]*Package
+	// Represents the package defining each model.
+	modelsMap map[*Model]*Package
+	errs      []string
+}
+
+func (r *resolver) report(pkg *Package, where, format string, args ...any) {
+	r.errs = append(r.errs, fmt.Sprintf("package '%s': %s: %s", pkg.Path, where, fmt.Sprintf(format, args...)))
+}
+
+func (r *resolver) pkg(pkg *Package) {
+	imports := make(map[*Package]bool)
+	typeRef := func(where, typ string) *TypeRef {
+		t, err := ParseType(typ)
+		if err != nil {
+			r.report(pkg, where, "%s", err)
+			return nil
+		}
+		r.link(pkg, t, where)
+		for _, ref := range t.Refs() {
+			imports[ref.Package] = true
+		}
+		return t
+	}
+	function := func(where string, fn *Function) {
+		for _, ps := range [][]*FnParameter{fn.Params, fn.Returns} {
+			for i, p := range ps {
+				name := p.Name
+				if name == "" {
+					name = fmt.Sprintf("#%d", i+1)
+				}
+				p.TypeRef = typeRef(fmt.Sprintf("%s, parameter '%s'", where, name), p.Type)
+			}
+		}
+	}
+
+	for i := range pkg.Models {
+		m := &pkg.Models[i]
+		where := fmt.Sprintf("model '%s'", m.Name)
+		for j := range m.Properties {
+			p := &m.Properties[j]
+			p.TypeRef = typeRef(fmt.Sprintf("%s, property '%s'", where, p.Name), p.Type)
+		}
+		for j := range m.Methods {
+			function(fmt.Sprintf("%s, method '%s'", where, m.Methods[j].Name), &m.Methods[j])
+		}
+		if m.Extends != "" {
+			m.ExtendsRef = r.target(pkg, where+", extends", m.Extends, false)
+		}
+		if m.Implements != "" {
+			m.ImplementsRef = r.target(pkg, where+", implements", m.Implements, true)
+		}
+		for _, t := range []*TypeRef{m.ExtendsRef, m.ImplementsRef} {
+			if t != nil && t.Package != nil {
+				imports[t.Package] = true
+			}
+		}
+	}
+	if pkg.Interface != nil {
+		for _, fn := range pkg.Interface.Methods {
+			function(fmt.Sprintf("interface, method '%s'", fn.Name), fn)
+		}
+	}
+	for _, e := range pkg.Endpoints {
+		where := fmt.Sprintf("endpoint '%s %s'", e.Method, e.Path)
+		for _, p := range append(append([]*EndpointParam{}, e.PathParams...), e.QueryParams...) {
+			p.TypeRef = typeRef(fmt.Sprintf("%s, parameter '%s'", where, p.Name), p.Type)
+		}
+	}
+
+	delete(imports, pkg)
+	pkg.Imports = make([]*Package, 0, len(imports))
+	for p := range imports {
+		pkg.Imports = append(pkg.Imports, p)
+	}
+	sort.Slice(pkg.Imports, func(i, j int) bool { return pkg.Imports[i].Path < pkg.Imports[j].Path })
+}
+
+// link links `t` and its type arguments to their definition.
+func (r *resolver) link(pkg *Package, t *TypeRef, where string) {
+	for _, a := range t.Args {
+		r.link(pkg, a, where)
+	}
+
+	if t.Qualifier == "" {
+		if n, ok := BuiltinTypes[t.Name]; ok {
+			if len(t.Args) != n {
+				r.report(pkg, where, "type '%s' expects %d type arguments, got %d", t.Name, n, len(t.Args))
+			}
+			return
+		}
+		if _, ok := r.spec.Config.Types[t.Name]; ok {
+			return
+		}
+		r.designate(pkg, t)
+	} else {
+		target, err := r.lookup(t.Qualifier)
+		if err != nil {
+			r.report(pkg, where, "%s", err)
+			return
+		}
+		if target == nil {
+			return // External type.
+		}
+		r.designate(target, t)
+	}
+
+	switch {
+	case t.Package == nil:
+		r.report(pkg, where, "unknown type '%s'", t)
+	case len(t.Args) != 0 && t.Interface != nil:
+		r.report(pkg, where, "interface '%s' does not expect type arguments", t.Name)
+	case len(t.Args) != 0:
+		r.report(pkg, where, "model '%s' does not expect type arguments", t.Name)
+	}
+}
+
+// designate links `t` to the model or interface of `target` it designates by name, if any.
+func (r *resolver) designate(target *Package, t *TypeRef) {
+	if m := target.Model(t.Name); m != nil {
+		t.Model, t.Package = m, target
+	} else if i := target.Interface; i != nil && i.Name == t.Name {
+		t.Interface, t.Package = i, target
+	}
+}
+
+// target returns the model (or interface, if `iface` is set) designated by `name`.
+func (r *resolver) target(pkg *Package, where, name string, iface bool) *TypeRef {
+	t, err := ParseType(name)
+	if err == nil && (len(t.Args) != 0 || t.Optional) {
+		err = errors.Errorf("invalid target '%s'", name)
+	}
+	if err != nil {
+		r.report(pkg, where, "%s", err)
+		return nil
+	}
+
+	target := pkg
+	if t.Qualifier != "" {
+		if target, err = r.lookup(t.Qualifier); err != nil {
+			r.report(pkg, where, "%s", err)
+			return nil
+		}
+		if target == nil {
+			return t // External target.
+		}
+	}
+	if m := target.Model(t.Name); m != nil {
+		t.Model, t.Package = m, target
+	} else if i := target.Interface; iface && i != nil && i.Name == t.Name {
+		t.Interface, t.Package = i, target
+	} else {
+		kind := "model"
+		if iface {
+			kind = "model or interface"
+		}
+		r.report(pkg, where, "unknown %s '%s'", kind, name)
+	}
+	return t
+}
+
+// lookup returns the package designated by `qualifier` (its path, or its name if unique); nil if none.
+func (r *resolver) lookup(qualifier string) (*Package, error) {
+	if pkg, ok := r.pkgsMap[qualifier]; ok {
+		return pkg, nil
+	}
+	switch pkgs := r.namesMap[qualifier]; len(pkgs) {
+	case 0:
+		return nil, nil
+	case 1:
+		return pkgs[0], nil
+	default:
+		paths := make([]string, 0, len(pkgs))
+		for _, p := range pkgs {
+			paths = append(paths, p.Path)
+		}
+		sort.Strings(paths)
+		return nil, errors.Errorf("ambiguous qualifier '%s'; use one of: %s", qualifier, strings.Join(paths, ", "))
+	}
+}
+
+// cycles reports the cycles in `Model.Extends` (e.g. 'A' extends 'B', which extends 'A').
+func (r *resolver) cycles() {
+	name := func(m *Model) string { return r.modelsMap[m].Path + "." + m.Name }
+	for _, pkg := range r.spec.Pkgs {
+		for i := range pkg.Models {
+			start := &pkg.Models[i]
+			chain := []string{name(start)}
+			for m, n := start, 0; m.ExtendsRef != nil && m.ExtendsRef.Model != nil && n <= len(r.modelsMap); n++ {
+				m = m.ExtendsRef.Model
+				chain = append(chain, name(m))
+				if m != start {
+					continue
+				}
+				// -> Report each cycle once, from its first model in alphabetical order.
+				first := chain[0]
+				for _, c := range chain {
+					if c < first {
+						first = c
+					}
+				}
+				if first == chain[0] {
+					r.report(pkg, fmt.Sprintf("model '%s'", start.Name), "cycle in 'extends': %s",
+						strings.Join(chain, " -> "))
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected string
+		err      bool
+	}{
+		{"string", "string", false},
+		{"User?", "User?", false},
+		{"billing.Invoice", "billing.Invoice", false},
+		{"billing/invoice.Invoice", "billing/invoice.Invoice", false},
+		{"map<string,list<billing.Invoice?>>?", "map<string,list<billing.Invoice?>>?", false},
+		{"\\*gorm.DB", "gorm.DB?", false},
+		{"[]*User", "list<User?>", false},
+		{"map[string][]int", "map<string,list<int>>", false},
+		{"list<string", "", true},
+		{"map[string", "", true},
+		{".Invoice", "", true},
+		{"list<>", "", true},
+		{"User??", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			ref, err := ParseType(test.input)
+			if test.err {
+				assert.NotEqual(t, err, nil)
+				return
+			}
+			assert.Equal(t, err, nil)
+			assert.Equal(t, ref.String(), test.expected)
+		})
+	}
+}
+
+func TestSpec_Resolve(t *testing.T) {
+	newModel := func(name string, props ...string) Model {
+		m := Model{EntityWithScope: EntityWithScope{Entity: Entity{Name: name}}}
+		for i := 0; i < len(props); i += 2 {
+			m.Properties = append(m.Properties, ModelProperty{
+				EntityWithScope: EntityWithScope{Entity: Entity{Name: props[i]}},
+				Type:            props[i+1],
+			})
+		}
+		return m
+	}
+	newSpec := func(pkgs ...*Package) *Spec {
+		return &Spec{Config: &Config{Types: map[string]map[string]string{"money": {"go": "decimal.Decimal"}}}, Pkgs: pkgs}
+	}
+	newPkg := func(path string, models ...Model) *Package {
+		name := path[strings.LastIndex(path, "/")+1:]
+		return &Package{Entity: Entity{Name: name}, Path: path, Models: models}
+	}
+
+	t.Run("resolved references", func(t *testing.T) {
+		invoice := newPkg("billing/invoice", newModel("Invoice", "total", "money", "issuer", "user.Service"))
+		user := newPkg("user",
+			newModel("Base", "db", "\\*gorm.DB"),
+			newModel("User", "id", "uuid", "invoices", "list<invoice.Invoice>", "last", "billing/invoice.Invoice?"),
+		)
+		user.Models[1].Extends = "Base"
+		user.Models[1].Implements = "Service"
+		user.Interface = &Interface{Name: "Service", Methods: []*Function{{
+			EntityWithScope: EntityWithScope{Entity: Entity{Name: "Find"}},
+			Params:          []*FnParameter{{Name: "fallback", Type: "Service"}},
+			Returns:         []*ReturnParameter{{Type: "User?"}},
+		}}}
+		spec := newSpec(user, invoice)
+
+		assert.Equal(t, spec.resolve(), nil)
+		u := &user.Models[1]
+		assert.Equal(t, u.ExtendsRef.Model, &user.Models[0])
+		assert.Equal(t, u.ImplementsRef.Interface, user.Interface)
+		assert.Equal(t, u.Properties[0].TypeRef.IsBuiltin(), true)
+		assert.Equal(t, u.Properties[1].TypeRef.Args[0].Model, &invoice.Models[0])
+		assert.Equal(t, u.Properties[1].TypeRef.Args[0].Package, invoice)
+		assert.Equal(t, u.Properties[2].TypeRef.Model, &invoice.Models[0])
+		assert.Equal(t, user.Models[0].Properties[0].TypeRef.IsExternal(), true)
+		assert.Equal(t, user.Interface.Methods[0].Returns[0].TypeRef.Model, u)
+		assert.Equal(t, user.Interface.Methods[0].Params[0].TypeRef.Interface, user.Interface)
+		assert.Equal(t, invoice.Models[0].Properties[1].TypeRef.Interface, user.Interface)
+		assert.Equal(t, invoice.Models[0].Properties[1].TypeRef.Package, user)
+		assert.Equal(t, user.Imports, []*Package{invoice})
+		assert.Equal(t, invoice.Imports, []*Package{user})
+	})
+
+	tests := []struct {
+		name string
+		spec func() *Spec
+		err  string
+	}{
+		{"unknown type", func() *Spec {
+			return newSpec(newPkg("user", newModel("User", "id", "identifier")))
+		}, "model 'User', property 'id': unknown type 'identifier'"},
+		{"interface type arguments", func() *Spec {
+			pkg := newPkg("user", newModel("User", "svc", "Service<string>"))
+			pkg.Interface = &Interface{Name: "Service"}
+			return newSpec(pkg)
+		}, "interface 'Service' does not expect type arguments"},
+		{"unknown qualified type", func() *Spec {
+			return newSpec(newPkg("user", newModel("User", "last", "invoice.Receipt")), newPkg("billing/invoice"))
+		}, "unknown type 'invoice.Receipt'"},
+		{"ambiguous qualifier", func() *Spec {
+			return newSpec(
+				newPkg("user", newModel("User", "last", "invoice.Invoice")),
+				newPkg("billing/invoice", newModel("Invoice")),
+				newPkg("legacy/invoice", newModel("Invoice")),
+			)
+		}, "ambiguous qualifier 'invoice'"},
+		{"type arguments", func() *Spec {
+			return newSpec(newPkg("user", newModel("User", "tags", "map<string>")))
+		}, "type 'map' expects 2 type arguments, got 1"},
+		{"unknown extends", func() *Spec {
+			pkg := newPkg("user", newModel("User"))
+			pkg.Models[0].Extends = "Base"
+			return newSpec(pkg)
+		}, "model 'User', extends: unknown model 'Base'"},
+		{"unknown implements", func() *Spec {
+			pkg := newPkg("user", newModel("User"))
+			pkg.Models[0].Implements = "Service"
+			return newSpec(pkg)
+		}, "model 'User', implements: unknown model or interface 'Service'"},
+		{"extends cycle", func() *Spec {
+			a, b := newPkg("a", newModel("A")), newPkg("b", newModel("B"))
+			a.Models[0].Extends, b.Models[0].Extends = "b.B", "a.A"
+			return newSpec(a, b)
+		}, "cycle in 'extends': a.A -> b.B -> a.A"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.spec().resolve()
+			assert.NotEqual(t, err, nil)
+			if !strings.Contains(err.Error(), test.err) {
+				t.Errorf("Expected error containing '%s', but got '%s'", test.err, err)
+			}
+		})
+	}
+}
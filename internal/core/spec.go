@@ -1,7 +1,10 @@
 package core
 
 import (
+	"path/filepath"
 	"sort"
+
+	"github.com/maxzaleski/codegen/internal/fs"
 )
 
 // Spec represents the specification for the current generation.
@@ -30,14 +33,54 @@ type Metadata struct {
 	CodegenDir string
 	// Location of the current working directory.
 	Cwd string
-	// Represents the last modified time of the packages (values in unix).
+	// Represents the last modified time of the packages, keyed by path (values in unix).
 	PkgsLastModifiedMap map[string]int64
 }
 
+// TemplatesDir returns the location of the templates library: the '.codegen/templates' directory.
+func (m *Metadata) TemplatesDir() string {
+	return filepath.Join(m.CodegenDir, TemplatesDir)
+}
+
+// TemplatePath returns the location of the template of the given name (see `ScopeJobTemplate.Name`).
+//
+// Relative names are searched for within the templates library first (e.g. 'model.tmpl' =>
+// '.codegen/templates/model.tmpl'), then relative to the working directory of the process.
+func (m *Metadata) TemplatePath(name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	if path := filepath.Join(m.TemplatesDir(), name); m.CodegenDir != "" && fs.FileExists(path) {
+		return path
+	}
+	if path, err := filepath.Abs(name); err == nil {
+		return path
+	}
+	return name
+}
+
 // Config represents the configuration for the current generation.
 type Config struct {
 	PkgDomain  *PkgDomain  `yaml:"pkg" validate:"dive"`
 	HttpDomain *HttpDomain `yaml:"http" validate:"dive"`
+	// Types declares the custom types packages may refer to, besides the built-in ones (see `BuiltinTypes`) and
+	// models; each is optionally mapped to its counterpart per file extension (e.g. 'money: {go: decimal.Decimal}').
+	// Built-in types may be listed as to override their mapping (e.g. 'uuid: {go: uuid.UUID}').
+	Types map[string]map[string]string `yaml:"types"`
+	// Vars represents the variables available to all jobs; scopes and jobs may override them (see `MergeVars`).
+	// Values may refer to environment variables (e.g. 'module: ${GO_MODULE}').
+	Vars Vars `yaml:"vars" validate:"dive,keys,varname,endkeys"`
+}
+
+// Scopes returns the scopes of both domains; 'http' scopes come first.
+func (c *Config) Scopes() []*DomainScope {
+	ds := make([]*DomainScope, 0)
+	for _, d := range []*Domain{c.HttpDomain, c.PkgDomain} {
+		if d != nil {
+			ds = append(ds, d.Scopes...)
+		}
+	}
+	return ds
 }
 
 type (
@@ -65,21 +108,31 @@ type (
 		// Override is a flag that indicates whether the current job should override an existing file.
 		Override bool `yaml:"override" validate:"boolean"`
 		// OverrideOn indicates whether the job should override an existing file based on provided conditions.
+		//
+		// Keys are package paths (see `Package.Path`); `OverrideOnWildcard` targets the package the job is generated for.
 		OverrideOn map[string]ScopeJobOverride `yaml:"override-on" validate:"omitempty,dive"`
 		Unique     bool                        `yaml:"unique" validate:"boolean"`
+		// Vars represents the variables of the job; they take precedence over those of its scope.
+		Vars Vars `yaml:"vars" validate:"dive,keys,varname,endkeys"`
 	}
 
+	// ScopeJobOverride represents the package sections which, when changed, trigger the regeneration of a file.
 	ScopeJobOverride struct {
 		Model     bool `yaml:"model"`
 		Interface bool `yaml:"interface"`
 	}
 
 	ScopeJobTemplate struct {
-		Primary bool   `yaml:"primary" validate:"boolean"`
-		Name    string `yaml:"name" validate:"required"`
+		Primary bool `yaml:"primary" validate:"boolean"`
+		// Name represents the location of the template; see `Metadata.TemplatePath`.
+		Name string `yaml:"name" validate:"required"`
 	}
 )
 
+// OverrideOnWildcard is the `override-on` key targeting the package a job is generated for; unique jobs target all
+// packages.
+const OverrideOnWildcard = "\\*"
+
 // Copy deep copies the struct instance.
 func (s *ScopeJob) Copy() *ScopeJob {
 	sCopy := *s
@@ -125,6 +178,9 @@ type (
 		Inline     bool        `yaml:"inline" validate:"boolean"`
 		Jobs       []*ScopeJob `yaml:"jobs" validate:"dive"`
 		ParentType DomainType  `yaml:"-"`
+
+		// Vars represents the variables of the scope's jobs; they take precedence over those of the configuration.
+		Vars Vars `yaml:"vars" validate:"dive,keys,varname,endkeys"`
 	}
 
 	DomainType string
@@ -135,11 +191,21 @@ const (
 	DomainTypePkg  DomainType = "domain_pkg"
 )
 
+// Key returns the key of the domain within the configuration; either 'pkg' or 'http'.
+func (t DomainType) Key() string {
+	if t == DomainTypeHttp {
+		return "http"
+	}
+	return "pkg"
+}
+
 // FnParameter represents a function argument.
 type FnParameter struct {
 	Name  string `yaml:"name" validate:"required,alphanum"`
 	Type  string `yaml:"type" validate:"required,alpha"`
 	Index int8   `yaml:"index" validate:"required,number,gt=-1"`
+	// TypeRef represents the resolved type; see `Spec.resolve`.
+	TypeRef *TypeRef `yaml:"-" json:"-" hash:"ignore"`
 }
 
 // ReturnParameter represents a function's return parameter.
@@ -148,13 +214,13 @@ type FnParameter struct {
 type ReturnParameter = FnParameter
 
 type Entity struct {
-	Name        string `yaml:"name"`
-	Description string `yaml:"description"`
+	Name        string `yaml:"name" validate:"required"`
+	Description string `yaml:"description,omitempty"`
 }
 
 type EntityWithScope struct {
 	Entity `yaml:",inline"`
-	Scope  EntityScope `yaml:"scope" validate:"enum:EntityScope"`
+	Scope  EntityScope `yaml:"scope,omitempty" validate:"enum:EntityScope"`
 }
 
 type EntityScope string
@@ -179,18 +245,44 @@ const (
 const UniquePkgAlias = "[unique]"
 
 type Package struct {
-	Entity    `yaml:",inline"`
+	Entity `yaml:",inline"`
+	// Path represents the qualified path of the package: its directory relative to '.codegen/pkg', joined with its
+	// name (e.g. 'billing/invoice'); it equals the name of top-level packages.
+	//
+	// Packages are identified by their path.
+	Path      string     `yaml:"-"`
 	Models    []Model    `yaml:"models,omitempty" validate:"dive"`
-	Interface *Interface `yaml:"interface" validate:"dive"`
+	Interface *Interface `yaml:"interface,omitempty" validate:"dive"`
+	// Endpoints represents the HTTP endpoints exposed by the package; see the 'http' domain.
+	Endpoints []*Endpoint `yaml:"endpoints,omitempty" validate:"dive"`
+
+	// Imports represents the other packages the package refers to, sorted by path; see `Spec.resolve`.
+	Imports []*Package `yaml:"-" json:"-" hash:"ignore"`
+}
+
+// Model returns the model of the given name; nil if the package does not define it.
+func (p *Package) Model(name string) *Model {
+	for i := range p.Models {
+		if p.Models[i].Name == name {
+			return &p.Models[i]
+		}
+	}
+	return nil
 }
 
 // Model represents a generic domain model.
 type Model struct {
 	EntityWithScope `yaml:",inline"`
-	Extends         string          `yaml:"extends"`
-	Implements      string          `yaml:"implements"`
-	Properties      []ModelProperty `yaml:"props,omitempty" validate:"dive"`
-	Methods         []Function      `yaml:"methods,omitempty" validate:"dive"`
+	// Extends represents the model extended by the model (e.g. 'Base', 'billing.Base').
+	Extends string `yaml:"extends,omitempty"`
+	// Implements represents the model or interface implemented by the model (e.g. 'Service', 'billing.Service').
+	Implements string          `yaml:"implements,omitempty"`
+	Properties []ModelProperty `yaml:"props,omitempty" validate:"dive"`
+	Methods    []Function      `yaml:"methods,omitempty" validate:"dive"`
+
+	// Represent the resolved `Extends` and `Implements`; see `Spec.resolve`.
+	ExtendsRef    *TypeRef `yaml:"-" json:"-" hash:"ignore"`
+	ImplementsRef *TypeRef `yaml:"-" json:"-" hash:"ignore"`
 }
 
 // ModelProperty represents a generic property definition.
@@ -198,6 +290,8 @@ type ModelProperty struct {
 	EntityWithScope `yaml:",inline" validate:"dive"`
 	Type            string                  `yaml:"type" validate:"required,proptype"`
 	Addons          *map[string]interface{} `yaml:"addons,omitempty"`
+	// TypeRef represents the resolved type; see `Spec.resolve`.
+	TypeRef *TypeRef `yaml:"-" json:"-" hash:"ignore"`
 }
 
 // Function represents a generic function definition.
@@ -213,11 +307,50 @@ func (m *Function) SortParams() {
 }
 
 func (m *Function) sort(s []*FnParameter) {
-	sort.Slice(s, func(i, j int) bool { return s[i].Index > s[j].Index })
+	sort.SliceStable(s, func(i, j int) bool { return s[i].Index < s[j].Index })
 }
 
 // Interface represents a generic interface definition.
 type Interface struct {
-	Description string      `yaml:"description"`
+	// Name represents the name models refer to when implementing the interface (see `Model.Implements`).
+	Name        string      `yaml:"name,omitempty"`
+	Description string      `yaml:"description,omitempty"`
 	Methods     []*Function `yaml:"methods,omitempty" validate:"dive"`
 }
+
+type (
+	// Endpoint represents an HTTP endpoint; its name designates the handler (e.g. 'GetUser').
+	Endpoint struct {
+		Entity `yaml:",inline"`
+		Method string `yaml:"method" validate:"required,httpmethod"`
+		// Path represents the route of the endpoint; parameters are enclosed in braces (e.g. '/users/{id}').
+		Path        string           `yaml:"path" validate:"required,httppath"`
+		PathParams  []*EndpointParam `yaml:"path-params,omitempty" validate:"dive"`
+		QueryParams []*EndpointParam `yaml:"query-params,omitempty" validate:"dive"`
+		// Request represents the name of the model of the request body (see `Model.Name`); empty if none.
+		Request   string              `yaml:"request,omitempty"`
+		Responses []*EndpointResponse `yaml:"responses,omitempty" validate:"dive"`
+		// Auth represents the authorization tags of the endpoint (e.g. 'admin'); empty if public.
+		Auth []string `yaml:"auth,omitempty" validate:"dive,required"`
+	}
+
+	// EndpointParam represents a path or query parameter.
+	EndpointParam struct {
+		Entity   `yaml:",inline"`
+		Type     string `yaml:"type" validate:"required,proptype"`
+		Required bool   `yaml:"required,omitempty"`
+		// TypeRef represents the resolved type; see `Spec.resolve`.
+		TypeRef *TypeRef `yaml:"-" json:"-" hash:"ignore"`
+	}
+
+	// EndpointResponse represents a response of an endpoint.
+	EndpointResponse struct {
+		Status      int    `yaml:"status" validate:"required,gte=100,lte=599"`
+		Description string `yaml:"description,omitempty"`
+		// Model represents the name of the model of the response body (see `Model.Name`); empty if none.
+		Model string `yaml:"model,omitempty"`
+	}
+)
+
+// HttpMethods represents the methods an endpoint may declare.
+var HttpMethods = []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}
@@ -1,7 +1,11 @@
 package core
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+
+	"github.com/go-playground/assert"
 )
 
 func TestScopeJobFileName_Assign(t *testing.T) {
@@ -67,3 +71,47 @@ func TestScopeJobFileName_Assign(t *testing.T) {
 	//	})
 	//}
 }
+
+func TestFunction_SortParams(t *testing.T) {
+	f := &Function{
+		Params:  []*FnParameter{{Name: "b", Index: 2}, {Name: "c", Index: 3}, {Name: "a", Index: 1}},
+		Returns: []*ReturnParameter{{Name: "err", Index: 2}, {Name: "user", Index: 1}},
+	}
+	f.SortParams()
+
+	names := func(ps []*FnParameter) (ns []string) {
+		for _, p := range ps {
+			ns = append(ns, p.Name)
+		}
+		return
+	}
+	assert.Equal(t, names(f.Params), []string{"a", "b", "c"})
+	assert.Equal(t, names(f.Returns), []string{"user", "err"})
+}
+
+func TestMetadata_TemplatePath(t *testing.T) {
+	dir := t.TempDir()
+	md := &Metadata{CodegenDir: filepath.Join(dir, ".codegen")}
+	if err := os.MkdirAll(filepath.Join(md.TemplatesDir(), "go"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(md.TemplatesDir(), "go", "model.tmpl"), nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{"go/model.tmpl", filepath.Join(md.TemplatesDir(), "go", "model.tmpl")},
+		{"go/service.tmpl", filepath.Join(wd, "go", "service.tmpl")},
+		{filepath.Join(dir, "model.tmpl"), filepath.Join(dir, "model.tmpl")},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, md.TemplatePath(test.name), test.expected)
+		})
+	}
+}
@@ -1,17 +1,23 @@
 package core
 
 import (
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/maxzaleski/codegen/internal/lib/moddedstring"
+	"github.com/maxzaleski/codegen/internal/lib/slice"
+	"github.com/pkg/errors"
 	"reflect"
 	"regexp"
 	"strings"
 )
 
 var (
-	dirLikeRegex  = regexp.MustCompile("(?i)^[a-z0-9/_]+$")
-	propTypeRegex = regexp.MustCompile("(?i)^[a-z0-9_-]+$")
-	fileNameRegex = regexp.MustCompile("(?i)^(?:[a-z0-9_-]+|\\\\\\{([a-z0-9.]+)\\\\}([a-z_-]+)?)\\.[a-z]+$")
+	dirLikeRegex   = regexp.MustCompile("(?i)^[a-z0-9/_]+$")
+	propTypeRegex  = regexp.MustCompile("(?i)^(?:\\\\\\*|[a-z0-9_./<>,?\\[\\]*-])+$")
+	varNameRegex   = regexp.MustCompile("(?i)^[a-z_][a-z0-9_]*$")
+	httpPathRegex  = regexp.MustCompile("(?i)^(?:/(?:[a-z0-9._~:-]+|\\{[a-z_][a-z0-9_]*\\}))*/?$")
+	pathParamRegex = regexp.MustCompile("\\{([a-zA-Z_][a-zA-Z0-9_]*)\\}")
+	fileNameRegex  = regexp.MustCompile("(?i)^[a-z0-9_-]+\\.[a-z]+$")
 )
 
 // newValidator returns a new instance of `validator.Validate`.
@@ -44,9 +50,10 @@ func newValidator() *validator.Validate {
 	})
 
 	// Define a custom validation tag for directory-like values as we only care to produce a valid directory path, not
-	// establishing whether the directory exists (see: 'dirpath').
+	// establishing whether the directory exists (see: 'dirpath'); tokens are masked as for file names.
 	_ = v.RegisterValidation("dirlike", func(fl validator.FieldLevel) bool {
-		return dirLikeRegex.MatchString(fl.Field().String())
+		dir := fl.Field().String()
+		return moddedstring.Validate(dir) == nil && dirLikeRegex.MatchString(moddedstring.Mask(dir))
 	})
 
 	// Define a custom validation tag for property type values.
@@ -54,20 +61,160 @@ func newValidator() *validator.Validate {
 		return propTypeRegex.MatchString(fl.Field().String())
 	})
 
-	// Define a custom validation tag for file names.
+	// Define a custom validation tag for variable names (e.g. parameters).
+	_ = v.RegisterValidation("varname", func(fl validator.FieldLevel) bool {
+		return varNameRegex.MatchString(fl.Field().String())
+	})
+
+	// Define a custom validation tag for HTTP methods.
+	_ = v.RegisterValidation("httpmethod", func(fl validator.FieldLevel) bool {
+		return slice.Contains(HttpMethods, fl.Field().String(), nil)
+	})
+
+	// Define a custom validation tag for HTTP paths (e.g. '/users/{id}').
+	_ = v.RegisterValidation("httppath", func(fl validator.FieldLevel) bool {
+		return strings.HasPrefix(fl.Field().String(), "/") && httpPathRegex.MatchString(fl.Field().String())
+	})
+
+	// Define a custom validation tag for file names; tokens are masked as to validate the literal parts of the name
+	// (see `moddedstring.Mask`).
 	_ = v.RegisterValidation("filename", func(fl validator.FieldLevel) bool {
-		// ssm indexing:
-		// • [0] - full match
-		// • [1] - `moddedstring` substring
-		ssm := fileNameRegex.FindStringSubmatch(fl.Field().String())
-		if len(ssm) == 0 {
-			return false
-		}
-		if mss := ssm[1]; mss != "" {
-			return moddedstring.Validate(strings.Split(mss, ".")[1:])
-		}
-		return true
+		name := fl.Field().String()
+		return moddedstring.Validate(name) == nil && fileNameRegex.MatchString(moddedstring.Mask(name))
 	})
 
 	return v
 }
+
+// validateTokens validates the tokens and modifiers of the scopes' outputs and the jobs' file names, as to report the
+// first invalid one precisely (see `moddedstring.Validate`); the 'dirlike' and 'filename' tags only designate the field.
+func validateTokens(c *Config) error {
+	for _, s := range c.Scopes() {
+		if err := moddedstring.Validate(s.Output); err != nil {
+			return errors.Wrapf(err, "scope '%s': invalid output '%s'", s.Key, s.Output)
+		}
+		for _, j := range s.Jobs {
+			if err := moddedstring.Validate(j.FileName); err != nil {
+				return errors.Wrapf(err, "scope '%s', job '%s': invalid file-name '%s'", s.Key, j.Key, j.FileName)
+			}
+			if s.ParentType != DomainTypeHttp || !j.Unique {
+				continue
+			}
+			// -> Unique jobs are executed once for all packages, hence for no model in particular, nor package (as to
+			// their output).
+			if moddedstring.References(j.FileName, moddedstring.TokenModel) {
+				return errors.Errorf("scope '%s', job '%s': unique jobs may not refer to the '%s' token",
+					s.Key, j.Key, moddedstring.TokenModel)
+			}
+			for _, t := range []string{moddedstring.TokenPkg, moddedstring.TokenModel} {
+				if moddedstring.References(s.Output, t) {
+					return errors.Errorf("scope '%s': output may not refer to the '%s' token, as job '%s' is unique",
+						s.Key, t, j.Key)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// validatePkg validates the given package: the indices of its function parameters, then its endpoints (fields and
+// consistency).
+func validatePkg(pkg *Package) error {
+	for i := range pkg.Models {
+		m := &pkg.Models[i]
+		for j := range m.Methods {
+			fn := &m.Methods[j]
+			if err := validateIndices(fmt.Sprintf("model '%s', method '%s'", m.Name, fn.Name), fn); err != nil {
+				return err
+			}
+		}
+	}
+	if pkg.Interface != nil {
+		for _, fn := range pkg.Interface.Methods {
+			if err := validateIndices(fmt.Sprintf("interface, method '%s'", fn.Name), fn); err != nil {
+				return err
+			}
+		}
+	}
+
+	routesMap, namesMap := make(map[string]bool), make(map[string]bool)
+	for _, e := range pkg.Endpoints {
+		if err := validate.Struct(e); err != nil {
+			return err
+		}
+		route := e.Method + " " + e.Path
+		if routesMap[route] {
+			return errors.Errorf("endpoint '%s' is defined twice", route)
+		}
+		routesMap[route] = true
+		if namesMap[e.Name] {
+			return errors.Errorf("endpoint '%s': name '%s' is already in use", route, e.Name)
+		}
+		namesMap[e.Name] = true
+
+		// -> Path parameters must appear in the path.
+		for _, p := range e.PathParams {
+			if !strings.Contains(e.Path, "{"+p.Name+"}") {
+				return errors.Errorf("endpoint '%s': path parameter '%s' does not appear in the path", route, p.Name)
+			}
+		}
+		// -> Models must be defined by the package.
+		models := []string{e.Request}
+		statusMap := make(map[int]bool)
+		for _, r := range e.Responses {
+			if statusMap[r.Status] {
+				return errors.Errorf("endpoint '%s': status %d is defined twice", route, r.Status)
+			}
+			statusMap[r.Status] = true
+			models = append(models, r.Model)
+		}
+		for _, m := range models {
+			if m != "" && pkg.Model(m) == nil {
+				return errors.Errorf("endpoint '%s': model '%s' is not defined by the package", route, m)
+			}
+		}
+	}
+	return nil
+}
+
+// validateIndices returns an error if parameters (or return parameters) of the function share the same index; those
+// omitted are ordered as declared (see `Function.SortParams`).
+func validateIndices(where string, fn *Function) error {
+	for i, ps := range [][]*FnParameter{fn.Params, fn.Returns} {
+		indicesMap := make(map[int8]bool)
+		for _, p := range ps {
+			if p.Index == 0 {
+				continue
+			}
+			if indicesMap[p.Index] {
+				kind := "parameter"
+				if i == 1 {
+					kind = "return parameter"
+				}
+				return errors.Errorf("%s: %s index %d is defined twice", where, kind, p.Index)
+			}
+			indicesMap[p.Index] = true
+		}
+	}
+	return nil
+}
+
+// normaliseEndpoints upper-cases the method of the endpoints, and declares the path parameters that appear in their
+// path, but are not declared (as required strings).
+func normaliseEndpoints(pkg *Package) {
+	for _, e := range pkg.Endpoints {
+		e.Method = strings.ToUpper(e.Method)
+		declared := make(map[string]bool)
+		for _, p := range e.PathParams {
+			p.Required, declared[p.Name] = true, true
+		}
+		for _, ssm := range pathParamRegex.FindAllStringSubmatch(e.Path, -1) {
+			if name := ssm[1]; !declared[name] {
+				e.PathParams = append(e.PathParams, &EndpointParam{
+					Entity: Entity{Name: name}, Type: "string", Required: true,
+				})
+				declared[name] = true
+			}
+		}
+	}
+}
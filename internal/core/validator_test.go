@@ -1,7 +1,10 @@
 package core
 
 import (
+	"strings"
 	"testing"
+
+	"github.com/go-playground/assert"
 )
 
 func TestDirLikeValidation(t *testing.T) {
@@ -16,6 +19,11 @@ func TestDirLikeValidation(t *testing.T) {
 		{"dir_like123", true},
 		{"dir like", false},
 		{"dir\\not\\like", false},
+		{"internal/\\{pkg.asSnake\\}/handler", true},
+		{"src/main/java/com/acme/\\{pkg.path.asLower\\}", true},
+		{"internal/\\{pkg.fooBar\\}", false},
+		{"internal/\\{pkg/handler", false},
+		{"../\\{pkg\\}", false},
 	}
 
 	val := newValidator()
@@ -38,6 +46,10 @@ func TestPropTypeValidation(t *testing.T) {
 		{"prop123", true},
 		{"123prop", true},
 		{"prop_type123", true},
+		{"list<map<string,User?>>", true},
+		{"billing.Invoice", true},
+		{"\\*gorm.DB", true},
+		{"[]string", true},
 		{"prop type", false},
 		{"prop\\not\\type", false},
 	}
@@ -60,8 +72,10 @@ func TestFileNameValidation(t *testing.T) {
 		{"file.java", true},
 		{"File.Java", true},
 		{"foo-bar.java", true},
-		{"\\{pkg.asTitle.asUpperCase\\}.java", true},
-		{"\\{pkg.asTitle.asUpperCase\\}Service.java", true},
+		{"\\{pkg.asUpper.asSnake\\}.java", true},
+		{"\\{pkg.asUpper.asSnake\\}Service.java", true},
+		{"\\{vars.api_version.asUpper\\}.java", true},
+		{"\\{vars\\}.java", false},
 		{"invalid/file.java", false},
 		{"file.java.invalid", false},
 		{"\\{token1.token2.token3\\}", false},
@@ -78,3 +92,155 @@ func TestFileNameValidation(t *testing.T) {
 		})
 	}
 }
+
+func TestValidateTokens(t *testing.T) {
+	config := func(output, fileName string, unique bool) *Config {
+		return &Config{HttpDomain: &HttpDomain{Scopes: []*DomainScope{{
+			Key:        "routes",
+			Output:     output,
+			ParentType: DomainTypeHttp,
+			Jobs:       []*ScopeJob{{Key: "routes", FileName: fileName, Unique: unique}},
+		}}}}
+	}
+
+	assert.Equal(t, validateTokens(config("internal/routes", "\\{model.plural\\}.go", false)), nil)
+	assert.Equal(t, validateTokens(config("internal/\\{pkg\\}", "routes.go", false)), nil)
+	assert.Equal(t, validateTokens(config("internal/routes", "\\{pkg.fooBar\\}.go", false)).Error(),
+		"scope 'routes', job 'routes': invalid file-name '\\{pkg.fooBar\\}.go': '\\{pkg.fooBar\\}' (position 0): token 'pkg': "+
+			"unknown modifier 'fooBar' (allowed: asLower, asUpper, asTitle, asSnake, asCamel, asKebab, plural, singular, trimPrefix, trimSuffix, asDot, asPath)")
+	assert.Equal(t, validateTokens(config("internal/\\{foo\\}", "routes.go", false)).Error(),
+		"scope 'routes': invalid output 'internal/\\{foo\\}': '\\{foo\\}' (position 9): unknown token 'foo' "+
+			"(allowed: pkg, scope, job, model, domain, vars)")
+	assert.NotEqual(t, validateTokens(config("internal/routes", "\\{model\\}.go", true)), nil)
+	assert.NotEqual(t, validateTokens(config("internal/\\{pkg\\}", "routes.go", true)), nil)
+}
+
+func TestHttpPathValidation(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"/", true},
+		{"/users", true},
+		{"/users/{id}/orders/", true},
+		{"/v1/users:search", true},
+		{"users", false},
+		{"/users/{}", false},
+		{"/users/{id", false},
+		{"/users?id=1", false},
+	}
+
+	val := newValidator()
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			if valid := val.Var(test.input, "httppath"); valid == nil != test.expected {
+				t.Errorf("Expected validation result %v for input '%s', but got %v", test.expected, test.input, valid)
+			}
+		})
+	}
+}
+
+func TestValidatePkg(t *testing.T) {
+	newPkg := func(eps ...*Endpoint) *Package {
+		return &Package{
+			Entity:    Entity{Name: "user"},
+			Models:    []Model{{EntityWithScope: EntityWithScope{Entity: Entity{Name: "User"}}}},
+			Endpoints: eps,
+		}
+	}
+	newEndpoint := func(name, method, path string) *Endpoint {
+		return &Endpoint{Entity: Entity{Name: name}, Method: method, Path: path}
+	}
+
+	tests := []struct {
+		name string
+		pkg  *Package
+		err  string
+	}{
+		{"valid", newPkg(
+			&Endpoint{
+				Entity: Entity{Name: "UpdateUser"}, Method: "PUT", Path: "/users/{id}",
+				PathParams: []*EndpointParam{{Entity: Entity{Name: "id"}, Type: "uuid"}},
+				Request:    "User",
+				Responses:  []*EndpointResponse{{Status: 200, Model: "User"}, {Status: 404}},
+				Auth:       []string{"admin"},
+			},
+		), ""},
+		{"unknown method", newPkg(newEndpoint("GetUser", "FETCH", "/users")), "httpmethod"},
+		{"invalid path", newPkg(newEndpoint("GetUser", "GET", "users")), "httppath"},
+		{"duplicate route", newPkg(newEndpoint("A", "GET", "/users"), newEndpoint("B", "GET", "/users")), "defined twice"},
+		{"duplicate name", newPkg(newEndpoint("A", "GET", "/users"), newEndpoint("A", "POST", "/users")), "already in use"},
+		{"undeclared path parameter", newPkg(&Endpoint{
+			Entity: Entity{Name: "A"}, Method: "GET", Path: "/users",
+			PathParams: []*EndpointParam{{Entity: Entity{Name: "id"}, Type: "string"}},
+		}), "does not appear in the path"},
+		{"unknown model", newPkg(&Endpoint{
+			Entity: Entity{Name: "A"}, Method: "POST", Path: "/users", Request: "Account",
+		}), "model 'Account'"},
+		{"invalid status", newPkg(&Endpoint{
+			Entity: Entity{Name: "A"}, Method: "GET", Path: "/users", Responses: []*EndpointResponse{{Status: 42}},
+		}), "status"},
+		{"duplicate status", newPkg(&Endpoint{
+			Entity: Entity{Name: "A"}, Method: "GET", Path: "/users",
+			Responses: []*EndpointResponse{{Status: 200}, {Status: 200}},
+		}), "status 200 is defined twice"},
+		{"duplicate parameter index", func() *Package {
+			pkg := newPkg()
+			pkg.Models[0].Methods = []Function{{
+				EntityWithScope: EntityWithScope{Entity: Entity{Name: "Rename"}},
+				Params:          []*FnParameter{{Name: "a", Type: "string", Index: 1}, {Name: "b", Type: "string", Index: 1}},
+			}}
+			return pkg
+		}(), "model 'User', method 'Rename': parameter index 1 is defined twice"},
+		{"duplicate return parameter index", func() *Package {
+			pkg := newPkg()
+			pkg.Interface = &Interface{Methods: []*Function{{
+				EntityWithScope: EntityWithScope{Entity: Entity{Name: "Find"}},
+				Params:          []*FnParameter{{Name: "id", Type: "string", Index: 1}},
+				Returns:         []*ReturnParameter{{Type: "User", Index: 1}, {Type: "error", Index: 1}},
+			}}}
+			return pkg
+		}(), "interface, method 'Find': return parameter index 1 is defined twice"},
+		{"omitted parameter indices", func() *Package {
+			pkg := newPkg()
+			pkg.Models[0].Methods = []Function{{
+				EntityWithScope: EntityWithScope{Entity: Entity{Name: "Rename"}},
+				Params:          []*FnParameter{{Name: "a", Type: "string"}, {Name: "b", Type: "string"}},
+			}}
+			return pkg
+		}(), ""},
+		{"package fields", func() *Package {
+			// -> Only endpoints are validated field by field (e.g. unnamed parameters are accepted).
+			pkg := newPkg()
+			pkg.Models[0].Methods = []Function{{Params: []*FnParameter{{Type: "list<string>"}}}}
+			return pkg
+		}(), ""},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := validatePkg(test.pkg)
+			if test.err == "" {
+				assert.Equal(t, err, nil)
+				return
+			}
+			assert.NotEqual(t, err, nil)
+			assert.Equal(t, strings.Contains(err.Error(), test.err), true)
+		})
+	}
+}
+
+func TestNormaliseEndpoints(t *testing.T) {
+	pkg := &Package{Endpoints: []*Endpoint{{
+		Method:     "get",
+		Path:       "/users/{id}/orders/{orderID}",
+		PathParams: []*EndpointParam{{Entity: Entity{Name: "orderID"}, Type: "int"}},
+	}}}
+	normaliseEndpoints(pkg)
+
+	e := pkg.Endpoints[0]
+	assert.Equal(t, e.Method, "GET")
+	assert.Equal(t, e.PathParams, []*EndpointParam{
+		{Entity: Entity{Name: "orderID"}, Type: "int", Required: true},
+		{Entity: Entity{Name: "id"}, Type: "string", Required: true},
+	})
+}
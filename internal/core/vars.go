@@ -0,0 +1,75 @@
+package core
+
+import (
+	"os"
+	"regexp"
+	"sort"
+
+	"github.com/pkg/errors"
+)
+
+// Vars represents user-defined variables, keyed by name (see `Config.Vars`).
+type Vars map[string]string
+
+// envRegex matches the references to environment variables: '${NAME}', or '${NAME:-default}'.
+var envRegex = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)(?::-([^}]*))?}`)
+
+// MergeVars merges the given variables; later ones take precedence (e.g. job variables over scope ones).
+func MergeVars(vs ...Vars) Vars {
+	merged := make(Vars)
+	for _, v := range vs {
+		for k, val := range v {
+			merged[k] = val
+		}
+	}
+	return merged
+}
+
+// interpolate replaces the references to environment variables within the values of `v` (e.g. '${API_VERSION}');
+// variables that are not set fall back to their default ('${NAME:-default}'), and are an error otherwise.
+func (v Vars) interpolate() error {
+	keys := make([]string, 0, len(v))
+	for k := range v {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys) // -> Deterministic error messages.
+
+	for _, k := range keys {
+		var err error
+		v[k] = envRegex.ReplaceAllStringFunc(v[k], func(s string) string {
+			m := envRegex.FindStringSubmatch(s)
+			if val, ok := os.LookupEnv(m[1]); ok {
+				return val
+			}
+			if len(m[0]) != len("${"+m[1]+"}") {
+				return m[2] // -> Default value, possibly empty (e.g. '${NAME:-}').
+			}
+			if err == nil {
+				err = errors.Errorf("variable '%s': environment variable '%s' is not set", k, m[1])
+			}
+			return s
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// interpolateVars interpolates the variables of the configuration, at every level (see `Vars.interpolate`).
+func (c *Config) interpolateVars() error {
+	if err := c.Vars.interpolate(); err != nil {
+		return errors.Wrap(err, "vars")
+	}
+	for _, s := range c.Scopes() {
+		if err := s.Vars.interpolate(); err != nil {
+			return errors.Wrapf(err, "scope '%s': vars", s.Key)
+		}
+		for _, j := range s.Jobs {
+			if err := j.Vars.interpolate(); err != nil {
+				return errors.Wrapf(err, "scope '%s', job '%s': vars", s.Key, j.Key)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestVars_Interpolate(t *testing.T) {
+	t.Setenv("CODEGEN_MODULE", "example.com/app")
+
+	tests := []struct {
+		value    string
+		expected string
+		err      bool
+	}{
+		{"v1", "v1", false},
+		{"${CODEGEN_MODULE}/internal", "example.com/app/internal", false},
+		{"${CODEGEN_UNSET:-v1}", "v1", false},
+		{"${CODEGEN_UNSET:-}", "", false},
+		{"$CODEGEN_MODULE", "$CODEGEN_MODULE", false},
+		{"${CODEGEN_UNSET}", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			v := Vars{"key": test.value}
+			err := v.interpolate()
+			assert.Equal(t, err != nil, test.err)
+			if !test.err {
+				assert.Equal(t, v["key"], test.expected)
+			}
+		})
+	}
+}
+
+func TestMergeVars(t *testing.T) {
+	merged := MergeVars(Vars{"a": "root", "b": "root"}, nil, Vars{"b": "job", "c": "job"})
+	assert.Equal(t, merged, Vars{"a": "root", "b": "job", "c": "job"})
+}
@@ -15,30 +15,60 @@ type (
 		ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
 		QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
 		RequiresSeed() bool
+		// ReadOnly returns true if the database cannot be written to.
+		ReadOnly() bool
 		Conn() *sql.DB
 	}
 
 	database struct {
-		conn   *sql.DB
-		logger slog.INamedLogger
-		seed   bool
+		conn     *sql.DB
+		logger   slog.INamedLogger
+		seed     bool
+		readOnly bool
 	}
 )
 
 // New returns a new implementation of `IDatabase`.
-func New(l slog.ILogger, location string) (IDatabase, error) {
+//
+// When `readOnly` is set, nothing is written to disk: an existing database is opened in read-only mode, otherwise an
+// empty in-memory database is used instead.
+func New(l slog.ILogger, location string, readOnly bool) (IDatabase, error) {
 	nl := slog.NewNamed(l, "diagnostics-db", slog.None)
 
+	dir := location + "/.run"
+	src := dir + "/diagnostics.db"
+	if readOnly {
+		return newReadOnly(nl, src)
+	}
+
 	nl.Log("init", "msg", "creating .run directory if it does not exist")
 
 	// -> Create the '.run' directory if it does not exist.
-	dir := location + "/.run"
-	src := dir + "/diagnostics.db"
 	ok, err := fs.CreateDirINE(dir)
 	if err != nil {
 		return nil, errors.Wrap(err, "diagnostics: failed to create '.run' directory")
 	}
 
+	return open(nl, src, ok, false)
+}
+
+func newReadOnly(nl slog.INamedLogger, src string) (IDatabase, error) {
+	if fs.FileExists(src) {
+		return open(nl, "file:"+src+"?mode=ro", false, true)
+	}
+
+	nl.Log("init", "msg", "database does not exist, using in-memory database", "src", src)
+
+	db, err := open(nl, ":memory:", true, false)
+	if err != nil {
+		return nil, err
+	}
+	// Each connection is given its own in-memory database; enforce a single connection.
+	db.Conn().SetMaxOpenConns(1)
+	return db, nil
+}
+
+func open(nl slog.INamedLogger, src string, seed, readOnly bool) (IDatabase, error) {
 	nl.Log("open", "msg", "opening database connection", "src", src)
 
 	// -> Open the database connection.
@@ -47,9 +77,10 @@ func New(l slog.ILogger, location string) (IDatabase, error) {
 		return nil, errors.Wrap(err, "diagnostics: failed to open database")
 	}
 	db := &database{
-		conn:   conn,
-		logger: nl,
-		seed:   ok,
+		conn:     conn,
+		logger:   nl,
+		seed:     seed,
+		readOnly: readOnly,
 	}
 	defer nl.Log("ready", "msg", "database ready")
 
@@ -79,3 +110,7 @@ func (c *database) Conn() *sql.DB {
 func (c *database) RequiresSeed() bool {
 	return c.seed
 }
+
+func (c *database) ReadOnly() bool {
+	return c.readOnly
+}
@@ -2,16 +2,25 @@ package embeds
 
 import (
 	"embed"
-	"github.com/pkg/errors"
+	"io/fs"
 	"text/template"
+
+	"github.com/pkg/errors"
 )
 
 //go:embed templates/*.tmpl
 var FS embed.FS
 
-// Link injects the internal utility template for a given extension into the primary one.
+// Link injects the internal utility templates of a given extension (e.g. 'templates/go_embeds.tmpl') into the primary
+// one; no-op if the extension has none.
+//
+// Templates defined thereafter take precedence over the utility ones of the same name.
 func Link(pt *template.Template, ext string) (t *template.Template, err error) {
-	t, err = pt.ParseFS(FS, ext+"_embeds.tmpl")
+	name := "templates/" + ext + "_embeds.tmpl"
+	if _, err = fs.Stat(FS, name); err != nil {
+		return pt, nil
+	}
+	t, err = pt.ParseFS(FS, name)
 	if err != nil {
 		err = errors.Wrapf(err, "failed to parse embeds for ext='%s'", ext)
 	}
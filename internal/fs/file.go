@@ -13,9 +13,13 @@ func CreateFile(dest string, b []byte) error {
 	if err != nil {
 		return errors.Wrapf(err, "failed to create file at '%s'", dest)
 	}
-	if _, err := f.Write(bytes.TrimSpace(b)); err != nil {
+	if _, err = f.Write(bytes.TrimSpace(b)); err != nil {
+		_ = f.Close()
 		return errors.Wrapf(err, "failed to write to file at '%s'", dest)
 	}
+	if err = f.Close(); err != nil {
+		return errors.Wrapf(err, "failed to close file at '%s'", dest)
+	}
 	return nil
 }
 
@@ -0,0 +1,514 @@
+package importer
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/pkg/errors"
+)
+
+// GoOptions represents the options of `FromGo`.
+type GoOptions struct {
+	// Name of the package; defaults to the name of the Go package.
+	Name string
+	// Interface designates the interface imported as the package's interface; defaults to the first one declared.
+	Interface string
+	// Unexported indicates whether unexported declarations should be imported.
+	Unexported bool
+}
+
+// wellKnownTypes maps the types of the standard library (and common modules) to their generic counterpart.
+var wellKnownTypes = map[string]string{
+	"time.Time":                   "datetime",
+	"time.Duration":               "duration",
+	"github.com/google/uuid.UUID": "uuid",
+}
+
+// FromGo parses the Go package located at `dir`, and returns its definition as a package specification:
+//
+// • structs are imported as models; their fields as properties, and their methods as functions.
+//
+// • an interface is imported as the package's interface; the others are skipped.
+//
+// Doc comments are imported as descriptions, and struct tags as the 'tags' addon of properties. Types are expressed
+// generically (e.g. `[]*User` => `list<User?>`); types that cannot be resolved (e.g. unavailable dependencies) retain
+// their Go representation. Members whose type has no generic counterpart (e.g. functions, channels) are skipped.
+func FromGo(dir string, opts GoOptions) (*Result, error) {
+	bp, err := build.ImportDir(dir, build.ImportComment)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to locate Go package at '%s'", dir)
+	}
+
+	fset := token.NewFileSet()
+	files := make([]*ast.File, 0, len(bp.GoFiles))
+	for _, name := range bp.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse Go file at '%s'", filepath.Join(dir, name))
+		}
+		files = append(files, f)
+	}
+
+	g := &goImporter{
+		opts: opts,
+		info: &types.Info{
+			Defs:  make(map[*ast.Ident]types.Object),
+			Types: make(map[ast.Expr]types.TypeAndValue),
+		},
+		exprs: make(map[token.Pos]ast.Expr),
+		docs:  make(map[token.Pos]*ast.CommentGroup),
+	}
+	// -> Type errors are tolerated (e.g. unavailable dependencies); see `goImporter.typeOf`.
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "source", nil),
+		Error:    func(error) {},
+	}
+	g.pkg, _ = conf.Check(bp.ImportPath, fset, files, g.info)
+
+	return g.walk(bp, files)
+}
+
+type goImporter struct {
+	opts GoOptions
+	pkg  *types.Package
+	info *types.Info
+	// Represents the type expressions and doc comments of fields and parameters, keyed by position.
+	exprs map[token.Pos]ast.Expr
+	docs  map[token.Pos]*ast.CommentGroup
+}
+
+func (g *goImporter) walk(bp *build.Package, files []*ast.File) (*Result, error) {
+	name := g.opts.Name
+	if name == "" {
+		name = bp.Name
+	}
+	res := &Result{
+		Package: &core.Package{
+			Entity: core.Entity{Name: name, Description: describe("Package "+bp.Name, bp.Doc)},
+		},
+	}
+	for _, f := range files {
+		g.index(f)
+	}
+
+	type iface struct {
+		obj *types.TypeName
+		typ *ast.InterfaceType
+		doc *ast.CommentGroup
+	}
+	ifaces, modelsMap := make([]iface, 0), make(map[string]int)
+
+	// -> Type declarations.
+	for _, f := range files {
+		for _, d := range f.Decls {
+			gd, ok := d.(*ast.GenDecl)
+			if !ok || gd.Tok != token.TYPE {
+				continue
+			}
+			for _, s := range gd.Specs {
+				ts := s.(*ast.TypeSpec)
+				obj, ok := g.info.Defs[ts.Name].(*types.TypeName)
+				if !ok || !g.include(obj) {
+					continue
+				}
+				doc := ts.Doc
+				if doc == nil && len(gd.Specs) == 1 {
+					doc = gd.Doc
+				}
+
+				switch t := ts.Type.(type) {
+				case *ast.StructType:
+					st, _ := obj.Type().Underlying().(*types.Struct)
+					modelsMap[obj.Name()] = len(res.Package.Models)
+					res.Package.Models = append(res.Package.Models, g.model(obj, st, doc, res))
+				case *ast.InterfaceType:
+					ifaces = append(ifaces, iface{obj, t, doc})
+				default:
+					res.Skipped = append(res.Skipped, fmt.Sprintf("type %s %s", obj.Name(), types.ExprString(ts.Type)))
+				}
+			}
+		}
+	}
+
+	// -> Methods.
+	for _, f := range files {
+		for _, d := range f.Decls {
+			fd, ok := d.(*ast.FuncDecl)
+			if !ok || fd.Recv == nil {
+				continue
+			}
+			fn, ok := g.info.Defs[fd.Name].(*types.Func)
+			if !ok || !g.include(fn) {
+				continue
+			}
+			recv := fn.Type().(*types.Signature).Recv().Type()
+			if p, ok := recv.(*types.Pointer); ok {
+				recv = p.Elem()
+			}
+			if n, ok := recv.(*types.Named); ok {
+				if i, ok := modelsMap[n.Obj().Name()]; ok {
+					m := &res.Package.Models[i]
+					if f, ok := g.function(n.Obj().Name(), fn, fd.Doc, res); ok {
+						m.Methods = append(m.Methods, f)
+					}
+				}
+			}
+		}
+	}
+
+	// -> Interface.
+	for _, it := range ifaces {
+		if res.Package.Interface != nil || (g.opts.Interface != "" && it.obj.Name() != g.opts.Interface) {
+			res.Skipped = append(res.Skipped, "interface "+it.obj.Name())
+			continue
+		}
+		res.Package.Interface = g.iface(it.obj, it.typ, it.doc, res)
+
+		// -> Link the models implementing the interface.
+		t, ok := it.obj.Type().Underlying().(*types.Interface)
+		if !ok || t.NumMethods() == 0 {
+			continue
+		}
+		for i := range res.Package.Models {
+			m := &res.Package.Models[i]
+			if obj, ok := g.pkg.Scope().Lookup(m.Name).(*types.TypeName); ok &&
+				types.Implements(types.NewPointer(obj.Type()), t) {
+				m.Implements = it.obj.Name()
+			}
+		}
+	}
+	if g.opts.Interface != "" && res.Package.Interface == nil {
+		return nil, errors.Errorf("interface '%s' is not declared by package '%s'", g.opts.Interface, bp.ImportPath)
+	}
+	return res, nil
+}
+
+// index records the type expressions and doc comments of fields and parameters.
+//
+// For named fields, the key is the position of the name; otherwise, the position of the type, as per `types.Var.Pos`.
+func (g *goImporter) index(f *ast.File) {
+	ast.Inspect(f, func(n ast.Node) bool {
+		fld, ok := n.(*ast.Field)
+		if !ok {
+			return true
+		}
+		doc := fld.Doc
+		if doc == nil {
+			doc = fld.Comment
+		}
+		if len(fld.Names) == 0 {
+			g.exprs[fld.Type.Pos()], g.docs[fld.Type.Pos()] = fld.Type, doc
+		}
+		for _, n := range fld.Names {
+			g.exprs[n.Pos()], g.docs[n.Pos()] = fld.Type, doc
+		}
+		return true
+	})
+}
+
+func (g *goImporter) include(obj types.Object) bool {
+	return g.opts.Unexported || obj.Exported()
+}
+
+func (g *goImporter) entity(name string, doc *ast.CommentGroup, exported bool) core.EntityWithScope {
+	e := core.EntityWithScope{Entity: core.Entity{Name: name}, Scope: scopeOf(exported)}
+	if doc != nil {
+		e.Description = describe(name, doc.Text())
+	}
+	return e
+}
+
+func (g *goImporter) model(obj *types.TypeName, st *types.Struct, doc *ast.CommentGroup, res *Result) core.Model {
+	m := core.Model{EntityWithScope: g.entity(obj.Name(), doc, obj.Exported())}
+	if st == nil {
+		return m
+	}
+	for i := 0; i < st.NumFields(); i++ {
+		f := st.Field(i)
+		if f.Embedded() {
+			// -> The first embedded type is imported as the model's parent.
+			if m.Extends != "" {
+				res.Skipped = append(res.Skipped, fmt.Sprintf("embedded field %s.%s", obj.Name(), f.Name()))
+				continue
+			}
+			t := f.Type()
+			if p, ok := t.(*types.Pointer); ok {
+				t = p.Elem()
+			}
+			m.Extends = f.Name()
+			if name, ok := g.typeName(t); ok {
+				m.Extends = name
+			}
+			continue
+		}
+		if !g.include(f) {
+			continue
+		}
+
+		t, ok := g.typeOf(f)
+		if !ok {
+			typ := g.typeString(f.Type())
+			if e, ok := g.exprs[f.Pos()]; ok {
+				typ = types.ExprString(e) // -> As declared, should the type be invalid.
+			}
+			res.Skipped = append(res.Skipped, fmt.Sprintf("field %s.%s %s", obj.Name(), f.Name(), typ))
+			continue
+		}
+		p := core.ModelProperty{
+			EntityWithScope: g.entity(f.Name(), g.docs[f.Pos()], f.Exported()),
+			Type:            t,
+		}
+		if tags := structTags(st.Tag(i)); len(tags) != 0 {
+			p.Addons = &map[string]interface{}{"tags": tags}
+		}
+		m.Properties = append(m.Properties, p)
+	}
+	return m
+}
+
+func (g *goImporter) iface(obj *types.TypeName, it *ast.InterfaceType, doc *ast.CommentGroup, res *Result) *core.Interface {
+	i := &core.Interface{Name: obj.Name()}
+	if doc != nil {
+		i.Description = describe(obj.Name(), doc.Text())
+	}
+
+	// -> Declared methods come first, in order of declaration; embedded ones follow.
+	seen := make(map[string]bool)
+	for _, f := range it.Methods.List {
+		for _, n := range f.Names {
+			if fn, ok := g.info.Defs[n].(*types.Func); ok && g.include(fn) {
+				if m, ok := g.function(obj.Name(), fn, f.Doc, res); ok {
+					i.Methods = append(i.Methods, ptr(m))
+				}
+				seen[fn.Name()] = true
+			}
+		}
+	}
+	if t, ok := obj.Type().Underlying().(*types.Interface); ok {
+		for j := 0; j < t.NumMethods(); j++ {
+			if fn := t.Method(j); !seen[fn.Name()] && g.include(fn) {
+				if m, ok := g.function(obj.Name(), fn, nil, res); ok {
+					i.Methods = append(i.Methods, ptr(m))
+				}
+			}
+		}
+	}
+	return i
+}
+
+// function returns the definition of a method of `owner`; false if one of its parameters cannot be represented, in
+// which case it is reported as skipped.
+func (g *goImporter) function(owner string, fn *types.Func, doc *ast.CommentGroup, res *Result) (core.Function, bool) {
+	sig := fn.Type().(*types.Signature)
+	params, ok := g.params(sig.Params())
+	returns, rok := g.params(sig.Results())
+	if !ok || !rok {
+		res.Skipped = append(res.Skipped, fmt.Sprintf("method %s.%s %s", owner, fn.Name(), g.typeString(sig)))
+		return core.Function{}, false
+	}
+	return core.Function{
+		EntityWithScope: g.entity(fn.Name(), doc, fn.Exported()),
+		Params:          params,
+		Returns:         returns,
+	}, true
+}
+
+// params returns the parameters of a signature; unnamed parameters are named after their type (e.g. `error` => `err`).
+//
+// False if the type of a parameter cannot be represented.
+func (g *goImporter) params(t *types.Tuple) ([]*core.FnParameter, bool) {
+	if t.Len() == 0 {
+		return nil, true
+	}
+	ps, used := make([]*core.FnParameter, 0, t.Len()), make(map[string]bool)
+	for i := 0; i < t.Len(); i++ {
+		v := t.At(i)
+		name := v.Name()
+		if name == "" || name == "_" || used[name] {
+			name = paramName(v.Type())
+			if used[name] {
+				name += strconv.Itoa(i + 1)
+			}
+		}
+		used[name] = true
+		typ, ok := g.typeOf(v)
+		if !ok {
+			return nil, false
+		}
+		ps = append(ps, &core.FnParameter{Name: name, Type: typ, Index: int8(i + 1)})
+	}
+	return ps, true
+}
+
+// typeOf returns the generic type of a field or parameter; its Go representation if it could not be resolved (e.g.
+// unavailable dependencies). False if it cannot be represented, or parsed as per `core.ParseType`.
+func (g *goImporter) typeOf(v *types.Var) (string, bool) {
+	name, ok := g.typeName(v.Type())
+	if !ok {
+		e, found := g.exprs[v.Pos()]
+		if !found || !invalid(v.Type()) {
+			return "", false
+		}
+		name = types.ExprString(e)
+	}
+	// -> The type must be understood by the resolver (e.g. `audit.Set[string]` is not).
+	if _, err := core.ParseType(name); err != nil {
+		return "", false
+	}
+	return name, true
+}
+
+// typeString returns the Go representation of `t`, as referred to from within the imported package (e.g.
+// `*template.Template`).
+func (g *goImporter) typeString(t types.Type) string {
+	return types.TypeString(t, func(p *types.Package) string {
+		if p == g.pkg {
+			return ""
+		}
+		return p.Name()
+	})
+}
+
+// typeName returns the generic representation of `t`; false if `t` is (or contains) an invalid type or a type without
+// generic counterpart (e.g. `func()`, `chan int`, `struct{}`).
+func (g *goImporter) typeName(t types.Type) (string, bool) {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		return t.Name(), t.Kind() != types.Invalid
+	case *types.Pointer:
+		name, ok := g.typeName(t.Elem())
+		return name + "?", ok
+	case *types.Slice:
+		if b, ok := t.Elem().(*types.Basic); ok && b.Kind() == types.Byte {
+			return "bytes", true
+		}
+		return g.generic("list", t.Elem())
+	case *types.Array:
+		return g.generic("list", t.Elem())
+	case *types.Map:
+		return g.generic("map", t.Key(), t.Elem())
+	case *types.Named:
+		obj := t.Obj()
+		name := obj.Name()
+		if p := obj.Pkg(); p != nil && p != g.pkg {
+			if wk, ok := wellKnownTypes[p.Path()+"."+name]; ok {
+				return wk, true
+			}
+			name = p.Name() + "." + name
+		} else if p == g.pkg {
+			// -> Local types other than structs and interfaces are not imported; refer to their underlying type
+			// instead (e.g. 'type Status string' => 'string').
+			switch t.Underlying().(type) {
+			case *types.Struct, *types.Interface:
+			default:
+				return g.typeName(t.Underlying())
+			}
+		}
+		if args := t.TypeArgs(); args.Len() != 0 {
+			ts := make([]types.Type, 0, args.Len())
+			for i := 0; i < args.Len(); i++ {
+				ts = append(ts, args.At(i))
+			}
+			return g.generic(name, ts...)
+		}
+		return name, true
+	case *types.Interface:
+		if t.Empty() {
+			return "any", true
+		}
+	}
+	return "", false
+}
+
+// invalid reports whether `t` is, or is composed of, an invalid type (e.g. `*audit.Trail` if 'audit' is unavailable).
+func invalid(t types.Type) bool {
+	switch t := types.Unalias(t).(type) {
+	case *types.Basic:
+		return t.Kind() == types.Invalid
+	case *types.Pointer:
+		return invalid(t.Elem())
+	case *types.Slice:
+		return invalid(t.Elem())
+	case *types.Array:
+		return invalid(t.Elem())
+	case *types.Map:
+		return invalid(t.Key()) || invalid(t.Elem())
+	}
+	return false
+}
+
+func (g *goImporter) generic(name string, args ...types.Type) (string, bool) {
+	names := make([]string, 0, len(args))
+	for _, a := range args {
+		n, ok := g.typeName(a)
+		if !ok {
+			return "", false
+		}
+		names = append(names, n)
+	}
+	return name + "<" + strings.Join(names, ",") + ">", true
+}
+
+func paramName(t types.Type) string {
+	if p, ok := t.(*types.Pointer); ok {
+		t = p.Elem()
+	}
+	n, ok := t.(*types.Named)
+	if !ok {
+		return "v"
+	}
+	if n.Obj().Pkg() == nil && n.Obj().Name() == "error" {
+		return "err"
+	}
+	name := n.Obj().Name()
+	if strings.ToUpper(name) == name {
+		return strings.ToLower(name)
+	}
+	r := []rune(name)
+	r[0] = unicode.ToLower(r[0])
+	return string(r)
+}
+
+// structTags parses a struct tag as per the `reflect.StructTag` convention (e.g. `json:"id" db:"id"`).
+func structTags(tag string) map[string]string {
+	tags := make(map[string]string)
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		i := strings.Index(tag, ":\"")
+		if i <= 0 {
+			break
+		}
+		key, rest := tag[:i], tag[i+1:]
+		// -> Locate the closing quote, skipping escaped ones.
+		j := 1
+		for j < len(rest) && rest[j] != '"' {
+			if rest[j] == '\\' {
+				j++
+			}
+			j++
+		}
+		if j >= len(rest) {
+			break
+		}
+		val, err := strconv.Unquote(rest[:j+1])
+		if err != nil {
+			break
+		}
+		tags[key], tag = val, rest[j+1:]
+	}
+	return tags
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
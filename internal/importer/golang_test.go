@@ -0,0 +1,214 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/scaffold"
+	"github.com/maxzaleski/codegen/internal/slog"
+)
+
+const goSrc = `// Package user manages the users of the application.
+package user
+
+import (
+	"context"
+	"time"
+
+	"example.com/unavailable/audit"
+)
+
+// Base represents the fields shared by all records.
+type Base struct {
+	CreatedAt time.Time
+}
+
+// User represents a registered user.
+type User struct {
+	Base
+	// ID uniquely identifies the user.
+	ID      string            ` + "`json:\"id\" db:\"user_id\"`" + `
+	Emails  []string
+	Manager *User
+	Labels  map[string]int
+	Trail   audit.Trail
+	Status  Status
+	Events  chan string
+	age     int
+}
+
+// Rename updates the name of the user.
+func (u *User) Rename(name string) error { return nil }
+
+func (u *User) touch() {}
+
+// Watch calls fn whenever the user changes.
+func (u *User) Watch(fn func()) {}
+
+// Service manages the lifecycle of users.
+type Service interface {
+	// FindByID returns the user matching the given ID.
+	FindByID(ctx context.Context, id string) (*User, error)
+	Delete(context.Context, string) error
+}
+
+// Renamer renames records.
+type Renamer interface {
+	Rename(name string) error
+}
+
+type Status int
+`
+
+func TestFromGo(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "user.go"), []byte(goSrc), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("exported declarations", func(t *testing.T) {
+		res, err := FromGo(dir, GoOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := res.Package
+		assert.Equal(t, pkg.Name, "user")
+		assert.Equal(t, pkg.Description, "manages the users of the application.")
+		assert.Equal(t, res.Skipped, []string{
+			"field User.Events chan string",
+			"type Status int",
+			"method User.Watch func(fn func())",
+			"interface Renamer",
+		})
+
+		assert.Equal(t, len(pkg.Models), 2)
+		u := pkg.Models[1]
+		assert.Equal(t, u.Name, "User")
+		assert.Equal(t, u.Description, "represents a registered user.")
+		assert.Equal(t, u.Scope, core.EntityScopePublic)
+		assert.Equal(t, u.Extends, "Base")
+		assert.Equal(t, u.Implements, "")
+
+		types := make(map[string]string)
+		for _, p := range u.Properties {
+			types[p.Name] = p.Type
+		}
+		assert.Equal(t, types, map[string]string{
+			"ID":      "string",
+			"Emails":  "list<string>",
+			"Manager": "User?",
+			"Labels":  "map<string,int>",
+			"Trail":   "audit.Trail",
+			"Status":  "int",
+		})
+		assert.Equal(t, u.Properties[0].Description, "uniquely identifies the user.")
+		assert.Equal(t, *u.Properties[0].Addons, map[string]interface{}{
+			"tags": map[string]string{"json": "id", "db": "user_id"},
+		})
+		assert.Equal(t, pkg.Models[0].Properties[0].Type, "datetime")
+
+		assert.Equal(t, len(u.Methods), 1)
+		assert.Equal(t, u.Methods[0].Name, "Rename")
+		assert.Equal(t, u.Methods[0].Params, []*core.FnParameter{{Name: "name", Type: "string", Index: 1}})
+		assert.Equal(t, u.Methods[0].Returns, []*core.ReturnParameter{{Name: "err", Type: "error", Index: 1}})
+
+		i := pkg.Interface
+		assert.Equal(t, i.Name, "Service")
+		assert.Equal(t, i.Description, "manages the lifecycle of users.")
+		assert.Equal(t, len(i.Methods), 2)
+		assert.Equal(t, i.Methods[0].Name, "FindByID")
+		assert.Equal(t, i.Methods[0].Description, "returns the user matching the given ID.")
+		assert.Equal(t, i.Methods[0].Params, []*core.FnParameter{
+			{Name: "ctx", Type: "context.Context", Index: 1},
+			{Name: "id", Type: "string", Index: 2},
+		})
+		assert.Equal(t, i.Methods[0].Returns, []*core.ReturnParameter{
+			{Name: "user", Type: "User?", Index: 1},
+			{Name: "err", Type: "error", Index: 2},
+		})
+		assert.Equal(t, i.Methods[1].Params, []*core.FnParameter{
+			{Name: "context", Type: "context.Context", Index: 1},
+			{Name: "v", Type: "string", Index: 2},
+		})
+	})
+
+	t.Run("selected interface and unexported declarations", func(t *testing.T) {
+		res, err := FromGo(dir, GoOptions{Name: "account", Interface: "Renamer", Unexported: true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := res.Package
+		assert.Equal(t, pkg.Name, "account")
+		assert.Equal(t, pkg.Interface.Name, "Renamer")
+		assert.Equal(t, pkg.Interface.Methods[0].Name, "Rename")
+		assert.Equal(t, pkg.Models[1].Implements, "Renamer")
+		assert.Equal(t, len(pkg.Models[1].Properties), 7)
+		assert.Equal(t, pkg.Models[1].Properties[6].Scope, core.EntityScopePrivate)
+		assert.Equal(t, len(pkg.Models[1].Methods), 2)
+	})
+
+	// -> Imported specifications are valid as is, including types referring to the interface; members the resolver
+	// cannot parse are skipped.
+	t.Run("valid specification", func(t *testing.T) {
+		src := t.TempDir()
+		b := []byte("package store\n\nimport (\n\t\"text/template\"\n\n\t\"example.com/unavailable/audit\"\n)\n\n" +
+			"type Record struct {\n\tOwner Store\n\tLayout *template.Template\n\tDone chan struct{}\n\t" +
+			"Tags audit.Set[string]\n}\n\ntype Store interface {\n\t" +
+			"Persist(s Store, r *Record) error\n\tRender(compile func() (*template.Template, error)) error\n}\n")
+		if err := os.WriteFile(filepath.Join(src, "store.go"), b, 0666); err != nil {
+			t.Fatal(err)
+		}
+		res, err := FromGo(src, GoOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, res.Skipped, []string{
+			"field Record.Done chan struct{}",
+			"field Record.Tags audit.Set[string]",
+			"method Store.Render func(compile func() (*template.Template, error)) error",
+		})
+		b, err = Marshal(res.Package)
+		assert.Equal(t, err, nil)
+
+		cwd := t.TempDir()
+		if _, err = scaffold.Write(cwd, "", scaffold.PresetGo, false); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(filepath.Join(cwd, core.DomainDir, "pkg", "store.yaml"), b, 0666); err != nil {
+			t.Fatal(err)
+		}
+		wd, err := os.Getwd()
+		if err != nil {
+			t.Fatal(err)
+		}
+		location, err := filepath.Rel(wd, cwd)
+		if err != nil {
+			t.Fatal(err)
+		}
+		spec, err := core.NewSpec(slog.New(false, time.Time{}), location)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(spec.Pkgs), 2)
+	})
+
+	t.Run("unknown interface", func(t *testing.T) {
+		_, err := FromGo(dir, GoOptions{Interface: "Repository"})
+		assert.NotEqual(t, err, nil)
+	})
+
+	t.Run("not a package", func(t *testing.T) {
+		_, err := FromGo(t.TempDir(), GoOptions{})
+		assert.NotEqual(t, err, nil)
+	})
+}
+
+func TestMarshal(t *testing.T) {
+	b, err := Marshal(&core.Package{Entity: core.Entity{Name: "user"}, Models: []core.Model{{
+		EntityWithScope: core.EntityWithScope{Entity: core.Entity{Name: "User"}, Scope: core.EntityScopePublic},
+	}}})
+	assert.Equal(t, err, nil)
+	assert.Equal(t, string(b), "name: user\nmodels:\n  - name: User\n    scope: public\n")
+}
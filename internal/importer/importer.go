@@ -0,0 +1,72 @@
+// Package importer produces package specifications ('.codegen/pkg') from existing sources.
+package importer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"gopkg.in/yaml.v3"
+)
+
+// Result represents the outcome of an import.
+type Result struct {
+	Package *core.Package
+	// Represents the declarations that could not be represented by the specification (e.g. a second interface).
+	Skipped []string
+}
+
+// Marshal encodes the given package as YAML, as expected within '.codegen/pkg'.
+func Marshal(pkg *core.Package) ([]byte, error) {
+	var b strings.Builder
+	enc := yaml.NewEncoder(&b)
+	enc.SetIndent(2)
+	if err := enc.Encode(pkg); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return []byte(b.String()), nil
+}
+
+// describe returns the description of an entity from its documentation.
+//
+// As per the specification's convention, descriptions omit the name of the entity (e.g. "Rename updates the name."
+// => "updates the name.").
+func describe(name, doc string) string {
+	doc = strings.Join(strings.Fields(doc), " ")
+	if rest := strings.TrimPrefix(doc, name+" "); rest != doc {
+		return rest
+	}
+	return doc
+}
+
+// scopeOf returns the scope of an entity as per its visibility.
+func scopeOf(exported bool) core.EntityScope {
+	if exported {
+		return core.EntityScopePublic
+	}
+	return core.EntityScopePrivate
+}
+
+// camel joins the alphanumeric words of `s` in camel case (e.g. 'page_size' => 'pageSize'); the first letter is
+// uppercased if `upper` is set. A leading initialism is lowercased as a whole (e.g. 'ID' => 'id').
+func camel(s string, upper bool) string {
+	words := strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) && !unicode.IsDigit(r) })
+	var b strings.Builder
+	for i, w := range words {
+		r := []rune(w)
+		switch {
+		case i == 0 && !upper && strings.ToUpper(w) == w:
+			b.WriteString(strings.ToLower(w))
+			continue
+		case i == 0 && !upper:
+			r[0] = unicode.ToLower(r[0])
+		default:
+			r[0] = unicode.ToUpper(r[0])
+		}
+		b.WriteString(string(r))
+	}
+	return b.String()
+}
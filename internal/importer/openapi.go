@@ -0,0 +1,648 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/lib/slice"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIOptions represents the options of `FromOpenAPI`.
+type OpenAPIOptions struct {
+	// Name of the package; defaults to the name of the document, without its extension.
+	Name string
+}
+
+// FromOpenAPI parses the OpenAPI 3 or JSON Schema document (YAML or JSON) located at `path`, and returns its
+// definition as a package specification:
+//
+// • object schemas ('components/schemas', '$defs', 'definitions') are imported as models; inline objects are imported
+// as models named after their parent (e.g. 'User.address' => 'UserAddress'), and other schemas are inlined.
+//
+// • operations are imported as the functions of the package's interface: path, query and header parameters, then the
+// request body, as parameters; the schema of the success response as return parameter. They are also imported as the
+// package's endpoints, along with their responses and security requirements (as auth tags).
+//
+// Types are expressed generically (e.g. an optional array of 'User' => `list<User>?`); `allOf` references are
+// imported as `Model.Extends`.
+func FromOpenAPI(path string, opts OpenAPIOptions) (*Result, error) {
+	bs, err := os.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read file at '%s'", path)
+	}
+	doc := &oaDocument{}
+	if err = yaml.Unmarshal(bs, doc); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal file at '%s'", path)
+	}
+	if doc.Swagger != "" || (doc.OpenAPI != "" && !strings.HasPrefix(doc.OpenAPI, "3.")) {
+		return nil, errors.Errorf("unsupported OpenAPI version in '%s'; expected 3.x", path)
+	}
+
+	name := opts.Name
+	if name == "" {
+		name = strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	}
+	c := &oaImporter{
+		doc:       doc,
+		schemas:   make(map[string]*jsonSchema),
+		names:     make(map[string]bool),
+		resolving: make(map[string]bool),
+		res: &Result{
+			Package: &core.Package{Entity: core.Entity{Name: name, Description: doc.Info.Description}},
+		},
+	}
+	if c.res.Package.Description == "" {
+		c.res.Package.Description = doc.Info.Title
+	}
+	for _, schemas := range []ordered[*jsonSchema]{doc.Components.Schemas, doc.Defs, doc.Definitions} {
+		for _, e := range schemas {
+			c.schemas[e.Key] = e.Value
+		}
+	}
+	if err = c.walk(); err != nil {
+		return nil, errors.Wrapf(err, "failed to import document at '%s'", path)
+	}
+	return c.res, nil
+}
+
+type (
+	oaDocument struct {
+		OpenAPI string `yaml:"openapi"`
+		Swagger string `yaml:"swagger"`
+		Info    struct {
+			Title       string `yaml:"title"`
+			Description string `yaml:"description"`
+		} `yaml:"info"`
+		Paths      ordered[*oaPathItem] `yaml:"paths"`
+		Security   []ordered[[]string]  `yaml:"security"`
+		Components struct {
+			Schemas       ordered[*jsonSchema]      `yaml:"schemas"`
+			Parameters    map[string]*oaParameter   `yaml:"parameters"`
+			RequestBodies map[string]*oaRequestBody `yaml:"requestBodies"`
+			Responses     map[string]*oaResponse    `yaml:"responses"`
+		} `yaml:"components"`
+
+		// JSON Schema documents.
+		Defs        ordered[*jsonSchema] `yaml:"$defs"`
+		Definitions ordered[*jsonSchema] `yaml:"definitions"`
+		Root        jsonSchema           `yaml:",inline"`
+	}
+
+	oaPathItem struct {
+		Parameters []*oaParameter `yaml:"parameters"`
+		Get        *oaOperation   `yaml:"get"`
+		Put        *oaOperation   `yaml:"put"`
+		Post       *oaOperation   `yaml:"post"`
+		Delete     *oaOperation   `yaml:"delete"`
+		Options    *oaOperation   `yaml:"options"`
+		Head       *oaOperation   `yaml:"head"`
+		Patch      *oaOperation   `yaml:"patch"`
+		Trace      *oaOperation   `yaml:"trace"`
+	}
+
+	oaOperation struct {
+		OperationID string               `yaml:"operationId"`
+		Summary     string               `yaml:"summary"`
+		Description string               `yaml:"description"`
+		Parameters  []*oaParameter       `yaml:"parameters"`
+		RequestBody *oaRequestBody       `yaml:"requestBody"`
+		Responses   ordered[*oaResponse] `yaml:"responses"`
+		// Security overrides the security requirements of the document; an empty list designates a public operation.
+		Security *[]ordered[[]string] `yaml:"security"`
+	}
+
+	oaParameter struct {
+		Ref         string      `yaml:"$ref"`
+		Name        string      `yaml:"name"`
+		In          string      `yaml:"in"`
+		Description string      `yaml:"description"`
+		Required    bool        `yaml:"required"`
+		Schema      *jsonSchema `yaml:"schema"`
+	}
+
+	oaRequestBody struct {
+		Ref         string                `yaml:"$ref"`
+		Description string                `yaml:"description"`
+		Required    bool                  `yaml:"required"`
+		Content     ordered[*oaMediaType] `yaml:"content"`
+	}
+
+	oaResponse struct {
+		Ref         string                `yaml:"$ref"`
+		Description string                `yaml:"description"`
+		Content     ordered[*oaMediaType] `yaml:"content"`
+	}
+
+	oaMediaType struct {
+		Schema *jsonSchema `yaml:"schema"`
+	}
+
+	jsonSchema struct {
+		Ref                  string               `yaml:"$ref"`
+		Type                 schemaTypes          `yaml:"type"`
+		Format               string               `yaml:"format"`
+		Title                string               `yaml:"title"`
+		Description          string               `yaml:"description"`
+		Properties           ordered[*jsonSchema] `yaml:"properties"`
+		Required             []string             `yaml:"required"`
+		Items                *jsonSchema          `yaml:"items"`
+		AdditionalProperties yaml.Node            `yaml:"additionalProperties"`
+		AllOf                []*jsonSchema        `yaml:"allOf"`
+		OneOf                []*jsonSchema        `yaml:"oneOf"`
+		AnyOf                []*jsonSchema        `yaml:"anyOf"`
+		Enum                 []interface{}        `yaml:"enum"`
+		Nullable             bool                 `yaml:"nullable"`
+	}
+
+	// schemaTypes represents the 'type' keyword; either a single type, or a list of types (e.g. `[string, "null"]`).
+	schemaTypes []string
+)
+
+func (t *schemaTypes) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind == yaml.ScalarNode {
+		*t = schemaTypes{n.Value}
+		return nil
+	}
+	return n.Decode((*[]string)(t))
+}
+
+// ordered represents a mapping whose order of declaration is retained.
+type ordered[T any] []entry[T]
+
+type entry[T any] struct {
+	Key   string
+	Value T
+}
+
+func (o *ordered[T]) UnmarshalYAML(n *yaml.Node) error {
+	if n.Kind != yaml.MappingNode {
+		return errors.Errorf("line %d: expected a mapping", n.Line)
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		var v T
+		if err := n.Content[i+1].Decode(&v); err != nil {
+			return err
+		}
+		*o = append(*o, entry[T]{n.Content[i].Value, v})
+	}
+	return nil
+}
+
+// operations returns the operations of the path in a fixed order.
+func (p *oaPathItem) operations() []entry[*oaOperation] {
+	ops := make([]entry[*oaOperation], 0)
+	for _, e := range []entry[*oaOperation]{
+		{"get", p.Get}, {"put", p.Put}, {"post", p.Post}, {"delete", p.Delete},
+		{"options", p.Options}, {"head", p.Head}, {"patch", p.Patch}, {"trace", p.Trace},
+	} {
+		if e.Value != nil {
+			ops = append(ops, e)
+		}
+	}
+	return ops
+}
+
+type oaImporter struct {
+	doc *oaDocument
+	res *Result
+	// Represents the named schemas, keyed by name.
+	schemas map[string]*jsonSchema
+	// Represents the names of the models, as to name inline models uniquely.
+	names map[string]bool
+	// Represents the schemas being inlined, as to detect cycles.
+	resolving map[string]bool
+}
+
+func (c *oaImporter) walk() error {
+	// -> Models; named ones are reserved first, as inline models are named after them.
+	models := make([]entry[*jsonSchema], 0)
+	for _, schemas := range []ordered[*jsonSchema]{c.doc.Components.Schemas, c.doc.Defs, c.doc.Definitions} {
+		for _, e := range schemas {
+			if e.Value != nil && e.Value.isModel() {
+				models = append(models, e)
+			}
+		}
+	}
+	// -> JSON Schema documents may define a model at the root.
+	if c.doc.OpenAPI == "" && c.doc.Root.isModel() {
+		name := c.doc.Root.Title
+		if name == "" {
+			name = c.res.Package.Name
+		}
+		models = append(models, entry[*jsonSchema]{camel(name, true), &c.doc.Root})
+	}
+	for _, e := range models {
+		c.names[e.Key] = true
+	}
+	for _, e := range models {
+		c.addModel(e.Key, e.Value)
+	}
+
+	// -> Operations; imported both as functions and endpoints.
+	fns := make([]*core.Function, 0)
+	for _, p := range c.doc.Paths {
+		if p.Value == nil {
+			continue
+		}
+		for _, op := range p.Value.operations() {
+			fn, ep, err := c.function(p.Key, op.Key, op.Value, p.Value.Parameters)
+			if err != nil {
+				return errors.Wrapf(err, "operation '%s %s'", strings.ToUpper(op.Key), p.Key)
+			}
+			fns = append(fns, fn)
+			c.res.Package.Endpoints = append(c.res.Package.Endpoints, ep)
+		}
+	}
+	if len(fns) != 0 {
+		c.res.Package.Interface = &core.Interface{Description: c.doc.Info.Title, Methods: fns}
+	}
+	return nil
+}
+
+// addModel appends the model defined by `s`; inline models it contains follow it.
+func (c *oaImporter) addModel(name string, s *jsonSchema) {
+	i := len(c.res.Package.Models)
+	c.res.Package.Models = append(c.res.Package.Models, core.Model{})
+
+	m := core.Model{EntityWithScope: entity(name, s.Description)}
+	props, required := s.Properties, s.Required
+	for _, sub := range s.AllOf {
+		// -> The first reference is imported as the model's parent; the properties of the others are merged.
+		if sub.Ref != "" && m.Extends == "" {
+			m.Extends = refName(sub.Ref)
+			continue
+		}
+		if sub.Ref != "" {
+			if sub = c.schemas[refName(sub.Ref)]; sub == nil {
+				continue
+			}
+		}
+		props, required = append(props, sub.Properties...), append(required, sub.Required...)
+	}
+	for _, p := range props {
+		if p.Value == nil {
+			continue
+		}
+		t := c.typeOf(p.Value, name+camel(p.Key, true))
+		if !slice.Contains(required, p.Key, nil) {
+			t = optional(t)
+		}
+		mp := core.ModelProperty{EntityWithScope: entity(p.Key, p.Value.Description), Type: t}
+		// -> Enums are either inline, or referenced (e.g. 'kind: {$ref: Kind}'); see `baseType`.
+		if enum := c.deref(p.Value).Enum; len(enum) != 0 {
+			mp.Addons = &map[string]interface{}{"enum": enum}
+		}
+		m.Properties = append(m.Properties, mp)
+	}
+
+	c.res.Package.Models[i] = m
+}
+
+// addInlineModel appends the model defined by the inline schema `s`, and returns its name; suffixed by a number if
+// `name` is already taken.
+func (c *oaImporter) addInlineModel(name string, s *jsonSchema) string {
+	base := name
+	for i := 2; c.names[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	c.names[name] = true
+	c.addModel(name, s)
+	return name
+}
+
+// typeOf returns the generic type of `s`; inline objects are imported as models named `inline`.
+func (c *oaImporter) typeOf(s *jsonSchema, inline string) string {
+	if s == nil {
+		return "any"
+	}
+	t := c.baseType(s, inline)
+	if s.nullable() {
+		t = optional(t)
+	}
+	return t
+}
+
+func (c *oaImporter) baseType(s *jsonSchema, inline string) string {
+	if s.Ref != "" {
+		name := refName(s.Ref)
+		// -> Schemas that are not models (e.g. enums) are inlined.
+		if rs, ok := c.schemas[name]; ok && !rs.isModel() && !c.resolving[name] {
+			c.resolving[name] = true
+			defer delete(c.resolving, name)
+			return c.typeOf(rs, name)
+		}
+		return name
+	}
+	if s.isModel() {
+		return c.addInlineModel(inline, s)
+	}
+
+	alts := make([]*jsonSchema, 0)
+	for _, a := range append(s.OneOf, s.AnyOf...) {
+		if !a.Type.is("null") || len(a.Type) != 1 {
+			alts = append(alts, a)
+		}
+	}
+	switch {
+	case len(alts) == 1:
+		return c.typeOf(alts[0], inline)
+	case len(alts) > 1:
+		return "any"
+	}
+
+	switch s.Type.primary() {
+	case "string":
+		switch s.Format {
+		case "date-time":
+			return "datetime"
+		case "date", "uuid", "duration":
+			return s.Format
+		case "byte", "binary":
+			return "bytes"
+		}
+		return "string"
+	case "integer":
+		if s.Format == "int32" || s.Format == "int64" {
+			return s.Format
+		}
+		return "int"
+	case "number":
+		if s.Format == "float" {
+			return "float32"
+		}
+		return "float64"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "list<" + c.typeOf(s.Items, inline+"Item") + ">"
+	case "object":
+		if ap := s.additionalProperties(); ap != nil {
+			return "map<string," + c.typeOf(ap, inline+"Value") + ">"
+		}
+		return "map<string,any>"
+	}
+	return "any"
+}
+
+// deref returns the schema designated by the reference of `s`, if it is not a model (e.g. an enum), as it is then
+// inlined; `s` otherwise.
+func (c *oaImporter) deref(s *jsonSchema) *jsonSchema {
+	for seen := make(map[string]bool); s.Ref != ""; {
+		name := refName(s.Ref)
+		rs, ok := c.schemas[name]
+		if !ok || rs == nil || rs.isModel() || seen[name] {
+			break
+		}
+		seen[name], s = true, rs
+	}
+	return s
+}
+
+// function returns the function and the endpoint of an operation.
+func (c *oaImporter) function(
+	path, method string, op *oaOperation, shared []*oaParameter,
+) (*core.Function, *core.Endpoint, error) {
+	name := camel(op.OperationID, true)
+	if name == "" {
+		name = operationName(method, path)
+	}
+	desc := op.Summary
+	if desc == "" {
+		desc = op.Description
+	}
+	fn := &core.Function{EntityWithScope: entity(name, desc)}
+	ep := &core.Endpoint{Entity: fn.Entity, Method: strings.ToUpper(method), Path: path, Auth: c.auth(op)}
+
+	// -> Parameters: operation parameters override the shared ones of the same name and location.
+	params := make([]*oaParameter, 0, len(shared)+len(op.Parameters))
+	for _, ps := range [][]*oaParameter{shared, op.Parameters} {
+		for _, p := range ps {
+			p, err := c.parameter(p)
+			if err != nil {
+				return nil, nil, err
+			}
+			replaced := false
+			for i, prev := range params {
+				if prev.Name == p.Name && prev.In == p.In {
+					params[i], replaced = p, true
+				}
+			}
+			if !replaced {
+				params = append(params, p)
+			}
+		}
+	}
+	for _, p := range params {
+		t := c.typeOf(p.Schema, name+camel(p.Name, true))
+		ept := &core.EndpointParam{
+			Entity: core.Entity{Name: p.Name, Description: p.Description}, Type: t, Required: p.Required,
+		}
+		switch p.In {
+		case "path":
+			ep.PathParams = append(ep.PathParams, ept)
+		case "query":
+			ep.QueryParams = append(ep.QueryParams, ept)
+		}
+		if !p.Required {
+			t = optional(t)
+		}
+		fn.Params = append(fn.Params, &core.FnParameter{Name: camel(p.Name, false), Type: t})
+	}
+
+	// -> Request body.
+	if rb := op.RequestBody; rb != nil {
+		if rb.Ref != "" {
+			if rb = c.doc.Components.RequestBodies[refName(rb.Ref)]; rb == nil {
+				return nil, nil, errors.Errorf("unresolved reference '%s'", op.RequestBody.Ref)
+			}
+		}
+		if s := mediaSchema(rb.Content); s != nil {
+			t := c.typeOf(s, name+"Request")
+			ep.Request = c.modelOf(t)
+			if !rb.Required {
+				t = optional(t)
+			}
+			fn.Params = append(fn.Params, &core.FnParameter{Name: paramNameOf(s, "body"), Type: t})
+		}
+	}
+
+	// -> Responses; the success response (the first '2XX' one, or the default one) is returned by the function.
+	var ret *core.ReturnParameter
+	for _, r := range op.Responses {
+		res := r.Value
+		if res != nil && res.Ref != "" {
+			if res = c.doc.Components.Responses[refName(r.Value.Ref)]; res == nil {
+				return nil, nil, errors.Errorf("unresolved reference '%s'", r.Value.Ref)
+			}
+		}
+		if res == nil {
+			continue
+		}
+		success := strings.HasPrefix(r.Key, "2") || (r.Key == "default" && ret == nil)
+
+		var t string
+		s := mediaSchema(res.Content)
+		if s != nil {
+			inline := name + "Response"
+			if !success {
+				inline += r.Key
+			}
+			t = c.typeOf(s, inline)
+		}
+		if success && ret == nil && s != nil {
+			ret = &core.ReturnParameter{Name: paramNameOf(s, "result"), Type: t}
+		}
+		// -> Endpoints only describe explicit status codes (e.g. not 'default' or '2XX').
+		if status, err := strconv.Atoi(r.Key); err == nil {
+			ep.Responses = append(ep.Responses, &core.EndpointResponse{
+				Status:      status,
+				Description: strings.Join(strings.Fields(res.Description), " "),
+				Model:       c.modelOf(t),
+			})
+		}
+	}
+	if ret != nil {
+		fn.Returns = append(fn.Returns, ret)
+	}
+
+	for i, p := range fn.Params {
+		p.Index = int8(i + 1)
+	}
+	for i, p := range fn.Returns {
+		p.Index = int8(i + 1)
+	}
+	return fn, ep, nil
+}
+
+// auth returns the names of the security schemes required by the operation; those of the document unless overridden.
+func (c *oaImporter) auth(op *oaOperation) []string {
+	reqs := c.doc.Security
+	if op.Security != nil {
+		reqs = *op.Security
+	}
+	names := make([]string, 0)
+	for _, req := range reqs {
+		for _, e := range req {
+			if !slice.Contains(names, e.Key, nil) {
+				names = append(names, e.Key)
+			}
+		}
+	}
+	if len(names) == 0 {
+		return nil
+	}
+	return names
+}
+
+// modelOf returns the name of the model designated by the type `t`; empty if `t` is not a model.
+func (c *oaImporter) modelOf(t string) string {
+	if t = strings.TrimSuffix(t, "?"); c.names[t] {
+		return t
+	}
+	return ""
+}
+
+func (c *oaImporter) parameter(p *oaParameter) (*oaParameter, error) {
+	if p.Ref == "" {
+		return p, nil
+	}
+	rp, ok := c.doc.Components.Parameters[refName(p.Ref)]
+	if !ok {
+		return nil, errors.Errorf("unresolved reference '%s'", p.Ref)
+	}
+	return rp, nil
+}
+
+// isModel returns true if the schema defines an object with properties, or extends another.
+func (s *jsonSchema) isModel() bool {
+	return len(s.Properties) != 0 || len(s.AllOf) > 1 || (len(s.AllOf) == 1 && s.Type.is("object"))
+}
+
+func (s *jsonSchema) nullable() bool {
+	return s.Nullable || s.Type.is("null")
+}
+
+func (s *jsonSchema) additionalProperties() *jsonSchema {
+	if s.AdditionalProperties.Kind != yaml.MappingNode {
+		return nil
+	}
+	ap := &jsonSchema{}
+	if err := s.AdditionalProperties.Decode(ap); err != nil {
+		return nil
+	}
+	return ap
+}
+
+func (t schemaTypes) is(name string) bool {
+	return slice.Contains(t, name, nil)
+}
+
+// primary returns the first type that is not 'null'.
+func (t schemaTypes) primary() string {
+	for _, name := range t {
+		if name != "null" {
+			return name
+		}
+	}
+	return ""
+}
+
+// mediaSchema returns the schema of the JSON media type, or of the first one declared.
+func mediaSchema(content ordered[*oaMediaType]) *jsonSchema {
+	for _, m := range content {
+		if strings.Contains(m.Key, "json") && m.Value != nil {
+			return m.Value.Schema
+		}
+	}
+	if len(content) != 0 && content[0].Value != nil {
+		return content[0].Value.Schema
+	}
+	return nil
+}
+
+// refName returns the name designated by a reference (e.g. '#/components/schemas/User' => 'User').
+func refName(ref string) string {
+	name := ref[strings.LastIndex(ref, "/")+1:]
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// paramNameOf returns the name of a parameter of schema `s`; named after the referenced model if any.
+func paramNameOf(s *jsonSchema, fallback string) string {
+	if s.Ref != "" {
+		return camel(refName(s.Ref), false)
+	}
+	return fallback
+}
+
+// operationName returns the name of an operation without identifier (e.g. 'GET /users/{id}' => 'GetUsersByID').
+func operationName(method, path string) string {
+	name := camel(method, true)
+	for _, seg := range strings.Split(path, "/") {
+		if strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			name += "By" + camel(strings.Trim(seg, "{}"), true)
+		} else {
+			name += camel(seg, true)
+		}
+	}
+	return name
+}
+
+func entity(name, desc string) core.EntityWithScope {
+	return core.EntityWithScope{
+		Entity: core.Entity{Name: name, Description: strings.Join(strings.Fields(desc), " ")},
+		Scope:  core.EntityScopePublic,
+	}
+}
+
+func optional(t string) string {
+	if strings.HasSuffix(t, "?") {
+		return t
+	}
+	return t + "?"
+}
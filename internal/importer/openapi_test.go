@@ -0,0 +1,238 @@
+package importer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+const openAPISrc = `openapi: 3.0.3
+info:
+  title: Users API
+security:
+  - bearer: []
+paths:
+  /users/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema: {type: string, format: uuid}
+    get:
+      operationId: getUser
+      summary: returns the user matching the given ID.
+      parameters:
+        - $ref: '#/components/parameters/Expand'
+      responses:
+        '200':
+          content:
+            application/json:
+              schema: {$ref: '#/components/schemas/User'}
+        '404':
+          description: not found.
+    put:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema: {$ref: '#/components/schemas/User'}
+      responses:
+        '204':
+          description: updated.
+  /users:
+    get:
+      operationId: list-users
+      security: []
+      parameters:
+        - {name: page_size, in: query, schema: {type: integer, format: int32}}
+      responses:
+        default:
+          content:
+            application/json:
+              schema: {type: array, items: {$ref: '#/components/schemas/User'}}
+components:
+  parameters:
+    Expand: {name: expand, in: query, schema: {type: boolean}}
+  schemas:
+    Status:
+      type: string
+      enum: [active, banned]
+    Kind:
+      type: string
+      enum: [admin, member]
+    Base:
+      type: object
+      properties:
+        created_at: {type: string, format: date-time}
+    User:
+      description: represents a registered user.
+      allOf:
+        - $ref: '#/components/schemas/Base'
+        - type: object
+          required: [id, status]
+          properties:
+            id: {type: string, format: uuid}
+            status: {$ref: '#/components/schemas/Status'}
+            kind: {$ref: '#/components/schemas/Kind'}
+            emails: {type: array, items: {type: string}}
+            nickname: {type: string, nullable: true}
+            labels: {type: object, additionalProperties: {type: integer}}
+            address:
+              type: object
+              properties:
+                city: {type: string}
+`
+
+const jsonSchemaSrc = `{
+  "title": "order",
+  "type": "object",
+  "required": ["id"],
+  "properties": {
+    "id": {"type": "integer", "format": "int64"},
+    "lines": {"type": "array", "items": {"$ref": "#/$defs/Line"}}
+  },
+  "$defs": {
+    "Line": {
+      "type": "object",
+      "properties": {"price": {"type": ["number", "null"]}}
+    }
+  }
+}`
+
+func TestFromOpenAPI(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, data string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	t.Run("openapi document", func(t *testing.T) {
+		res, err := FromOpenAPI(write("users.yaml", openAPISrc), OpenAPIOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := res.Package
+		assert.Equal(t, pkg.Name, "users")
+		assert.Equal(t, pkg.Description, "Users API")
+
+		names := make([]string, 0)
+		for _, m := range pkg.Models {
+			names = append(names, m.Name)
+		}
+		assert.Equal(t, names, []string{"Base", "User", "UserAddress"})
+
+		u := pkg.Models[1]
+		assert.Equal(t, u.Description, "represents a registered user.")
+		assert.Equal(t, u.Extends, "Base")
+		types := make(map[string]string)
+		for _, p := range u.Properties {
+			types[p.Name] = p.Type
+		}
+		assert.Equal(t, types, map[string]string{
+			"id":       "uuid",
+			"status":   "string",
+			"kind":     "string?",
+			"emails":   "list<string>?",
+			"nickname": "string?",
+			"labels":   "map<string,int>?",
+			"address":  "UserAddress?",
+		})
+		assert.Equal(t, pkg.Models[0].Properties[0].Type, "datetime?")
+
+		// -> Referenced enums are inlined, values included.
+		assert.Equal(t, *u.Properties[1].Addons, map[string]interface{}{"enum": []interface{}{"active", "banned"}})
+		assert.Equal(t, *u.Properties[2].Addons, map[string]interface{}{"enum": []interface{}{"admin", "member"}})
+
+		fns := pkg.Interface.Methods
+		assert.Equal(t, len(fns), 3)
+		assert.Equal(t, fns[0].Name, "GetUser")
+		assert.Equal(t, fns[0].Description, "returns the user matching the given ID.")
+		assert.Equal(t, fns[0].Params, []*core.FnParameter{
+			{Name: "id", Type: "uuid", Index: 1},
+			{Name: "expand", Type: "bool?", Index: 2},
+		})
+		assert.Equal(t, fns[0].Returns, []*core.ReturnParameter{{Name: "user", Type: "User", Index: 1}})
+
+		assert.Equal(t, fns[1].Name, "PutUsersById")
+		assert.Equal(t, fns[1].Params[1], &core.FnParameter{Name: "user", Type: "User", Index: 2})
+		assert.Equal(t, len(fns[1].Returns), 0)
+
+		assert.Equal(t, fns[2].Name, "ListUsers")
+		assert.Equal(t, fns[2].Params, []*core.FnParameter{{Name: "pageSize", Type: "int32?", Index: 1}})
+		assert.Equal(t, fns[2].Returns, []*core.ReturnParameter{{Name: "result", Type: "list<User>", Index: 1}})
+
+		eps := pkg.Endpoints
+		assert.Equal(t, len(eps), 3)
+		assert.Equal(t, eps[0], &core.Endpoint{
+			Entity:      core.Entity{Name: "GetUser", Description: "returns the user matching the given ID."},
+			Method:      "GET",
+			Path:        "/users/{id}",
+			PathParams:  []*core.EndpointParam{{Entity: core.Entity{Name: "id"}, Type: "uuid", Required: true}},
+			QueryParams: []*core.EndpointParam{{Entity: core.Entity{Name: "expand"}, Type: "bool"}},
+			Responses: []*core.EndpointResponse{
+				{Status: 200, Model: "User"},
+				{Status: 404, Description: "not found."},
+			},
+			Auth: []string{"bearer"},
+		})
+		assert.Equal(t, eps[1].Request, "User")
+		assert.Equal(t, eps[1].Responses, []*core.EndpointResponse{{Status: 204, Description: "updated."}})
+		assert.Equal(t, eps[2].Auth, []string(nil))
+		assert.Equal(t, len(eps[2].Responses), 0)
+	})
+
+	t.Run("json schema document", func(t *testing.T) {
+		res, err := FromOpenAPI(write("order.json", jsonSchemaSrc), OpenAPIOptions{Name: "billing"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		pkg := res.Package
+		assert.Equal(t, pkg.Name, "billing")
+		assert.Equal(t, len(pkg.Models), 2)
+		assert.Equal(t, pkg.Models[0].Properties[0].Type, "float64?")
+		assert.Equal(t, pkg.Models[1].Name, "Order")
+		assert.Equal(t, pkg.Models[1].Properties[0].Type, "int64")
+		assert.Equal(t, pkg.Models[1].Properties[1].Type, "list<Line>?")
+		assert.Equal(t, pkg.Interface, (*core.Interface)(nil))
+	})
+
+	t.Run("invalid documents", func(t *testing.T) {
+		cases := []struct {
+			name string
+			data string
+		}{
+			{"swagger", "swagger: '2.0'"},
+			{"unresolved reference", "openapi: 3.1.0\npaths:\n  /a:\n    get:\n      parameters: [{$ref: '#/components/parameters/X'}]"},
+			{"malformed", "openapi: [3"},
+		}
+		for _, c := range cases {
+			t.Run(c.name, func(t *testing.T) {
+				_, err := FromOpenAPI(write("api.yaml", c.data), OpenAPIOptions{})
+				assert.NotEqual(t, err, nil)
+			})
+		}
+	})
+}
+
+func TestCamel(t *testing.T) {
+	cases := []struct {
+		in    string
+		upper bool
+		want  string
+	}{
+		{"page_size", false, "pageSize"},
+		{"X-Request-ID", false, "xRequestID"},
+		{"ID", false, "id"},
+		{"getUser", true, "GetUser"},
+		{"list-users", true, "ListUsers"},
+	}
+	for _, c := range cases {
+		assert.Equal(t, camel(c.in, c.upper), c.want)
+	}
+}
@@ -81,7 +81,10 @@ func NewQueue[J any](logger slog.INamedLogger, workerCount int, hooks *QueueHook
 }
 
 func (q *queue[J]) Enqueue(j *J) {
-	if q.isClosed {
+	q.mu.Lock()
+	closed := q.isClosed
+	q.mu.Unlock()
+	if closed {
 		panic("queue is closed")
 	}
 	defer q.tryHook(q.hooks.OnEnqueue, j)
@@ -90,12 +93,10 @@ func (q *queue[J]) Enqueue(j *J) {
 }
 
 func (q *queue[J]) Dequeue() *J {
-	j := <-q.collection // channels are thread-safe by default.
-	if j == nil {
-		if q.isClosed {
-			return nil
-		}
-		panic("queue: race condition")
+	// -> The channel is only drained once closed; `ok` is false thereafter.
+	j, ok := <-q.collection // channels are thread-safe by default.
+	if !ok {
+		return nil
 	}
 	defer q.tryHook(q.hooks.OnEnqueue, j)
 
@@ -111,6 +112,9 @@ func (q *queue[J]) GetCapacity() int {
 }
 
 func (q *queue[J]) GetReady() bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
 	return q.isReady
 }
 
@@ -119,7 +123,9 @@ func (q *queue[J]) Ready() {
 		defer q.logState("ready", "queue is ready")
 
 		close(q.readyChan)
+		q.mu.Lock()
 		q.isReady = true
+		q.mu.Unlock()
 	})
 }
 
@@ -0,0 +1,42 @@
+package datastructure
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/slog"
+)
+
+func TestQueue_Dequeue(t *testing.T) {
+	l := slog.NewNamed(slog.New(false, time.Time{}), "queue", slog.None)
+
+	// -> Jobs enqueued prior to closing the queue are still dequeued; consumers are then released.
+	q := NewQueue[int](l, 4, &QueueHooks[int]{})
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		dequeued int
+	)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := q.Dequeue(); j != nil; j = q.Dequeue() {
+				mu.Lock()
+				dequeued += *j
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := 1; i <= 100; i++ {
+		v := i
+		q.Enqueue(&v)
+	}
+	q.Close()
+	wg.Wait()
+
+	assert.Equal(t, dequeued, 5050)
+	assert.Equal(t, q.Dequeue() == nil, true)
+}
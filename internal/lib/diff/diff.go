@@ -0,0 +1,254 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultContext is the number of unchanged lines surrounding each hunk.
+const DefaultContext = 3
+
+type (
+	opKind int
+
+	// op represents a single line operation of an edit script.
+	op struct {
+		kind opKind
+		// Position of the operation in the source (a) and destination (b) texts.
+		a, b int
+		line string
+	}
+)
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+// Unified returns the unified diff between texts `a` and `b`, or an empty string if they are identical.
+//
+// `from` and `to` are the file names used in the diff header (e.g. 'a/main.go', 'b/main.go').
+func Unified(from, to string, a, b []byte, context int) string {
+	if string(a) == string(b) {
+		return ""
+	}
+	ops := compute(splitLines(string(a)), splitLines(string(b)))
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", from, to))
+	for _, h := range hunks(ops, context) {
+		writeHunk(&sb, ops[h[0]:h[1]])
+	}
+	return sb.String()
+}
+
+// splitLines splits the given text into lines, retaining their line terminator.
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// compute returns the shortest edit script transforming `a` into `b`.
+//
+// Myers' algorithm, in its linear space refinement: rather than retaining the furthest reaching paths of every step
+// (O((n+m)²) memory), the middle snake of the edit graph splits the problem in two, recursively (O(n+m) memory).
+func compute(a, b []string) []op {
+	return diffRange(make([]op, 0, len(a)+len(b)), a, b, 0, 0)
+}
+
+// diffRange appends the edit script transforming `a` into `b` to `ops`; `ax` and `bx` are the positions of `a` and
+// `b` within the original texts.
+func diffRange(ops []op, a, b []string, ax, bx int) []op {
+	// [1] Common prefix.
+	p := 0
+	for ; p < len(a) && p < len(b) && a[p] == b[p]; p++ {
+		ops = append(ops, op{kind: opEqual, a: ax + p, b: bx + p, line: a[p]})
+	}
+	a, b, ax, bx = a[p:], b[p:], ax+p, bx+p
+
+	// [2] Common suffix; appended last.
+	s := 0
+	for s < len(a) && s < len(b) && a[len(a)-1-s] == b[len(b)-1-s] {
+		s++
+	}
+	n, m := len(a)-s, len(b)-s
+
+	// [3] Differing middle.
+	switch {
+	case n == 0:
+		for j := 0; j < m; j++ {
+			ops = append(ops, op{kind: opInsert, a: ax, b: bx + j, line: b[j]})
+		}
+	case m == 0:
+		for i := 0; i < n; i++ {
+			ops = append(ops, op{kind: opDelete, a: ax + i, b: bx, line: a[i]})
+		}
+	default:
+		x, y := middleSnake(a[:n], b[:m])
+		ops = diffRange(ops, a[:x], b[:y], ax, bx)
+		ops = diffRange(ops, a[x:n], b[y:m], ax+x, bx+y)
+	}
+
+	for i := 0; i < s; i++ {
+		ops = append(ops, op{kind: opEqual, a: ax + n + i, b: bx + m + i, line: a[n+i]})
+	}
+	return ops
+}
+
+// middleSnake returns a point `(x, y)` of a shortest edit path of `a` into `b`, found where the paths explored from
+// both ends of the edit graph overlap; `a` and `b` are non-empty, and differ on their first and last lines.
+//
+// If the paths do not overlap (i.e. `a` and `b` have no line in common), `(len(a), 0)` is returned: all lines of `a`
+// are deleted, then all lines of `b` are inserted.
+func middleSnake(a, b []string) (int, int) {
+	n, m := len(a), len(b)
+	maxD := (n + m + 1) / 2
+	offset, size := maxD, 2*maxD+2
+
+	// vf[k] (resp. vr[k]) holds the furthest reaching x of diagonal k, from the start (resp. the end); -1 if unreached.
+	vf, vr := make([]int, size), make([]int, size)
+	for i := range vf {
+		vf[i], vr[i] = -1, -1
+	}
+	vf[offset+1], vr[offset+1] = 0, 0
+
+	delta := n - m
+	// -> If delta is odd, the paths meet during a forward step; otherwise, during a reverse one.
+	front := delta%2 != 0
+	// -> Diagonals beyond the edit graph are no longer explored.
+	kfStart, kfEnd, krStart, krEnd := 0, 0, 0, 0
+
+	for d := 0; d < maxD; d++ {
+		for k := -d + kfStart; k <= d-kfEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vf[offset+k-1] < vf[offset+k+1]) {
+				x = vf[offset+k+1] // move down (insertion).
+			} else {
+				x = vf[offset+k-1] + 1 // move right (deletion).
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x, y = x+1, y+1
+			}
+			vf[offset+k] = x
+
+			if x > n {
+				kfEnd += 2
+			} else if y > m {
+				kfStart += 2
+			} else if front {
+				if kr := offset + delta - k; kr >= 0 && kr < size && vr[kr] != -1 && x >= n-vr[kr] {
+					return x, y
+				}
+			}
+		}
+
+		for k := -d + krStart; k <= d-krEnd; k += 2 {
+			var x int
+			if k == -d || (k != d && vr[offset+k-1] < vr[offset+k+1]) {
+				x = vr[offset+k+1]
+			} else {
+				x = vr[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && a[n-x-1] == b[m-y-1] {
+				x, y = x+1, y+1
+			}
+			vr[offset+k] = x
+
+			if x > n {
+				krEnd += 2
+			} else if y > m {
+				krStart += 2
+			} else if !front {
+				if kf := offset + delta - k; kf >= 0 && kf < size && vf[kf] != -1 && vf[kf] >= n-x {
+					return vf[kf], vf[kf] - (kf - offset)
+				}
+			}
+		}
+	}
+	return n, 0
+}
+
+// hunks groups the changes of the edit script into ranges `[start, end)`, including up to `context` unchanged
+// lines on each side.
+func hunks(ops []op, context int) [][2]int {
+	hs := make([][2]int, 0)
+	for i := 0; i < len(ops); i++ {
+		if ops[i].kind == opEqual {
+			continue
+		}
+		start, end := i-context, i+1
+		if start < 0 {
+			start = 0
+		}
+		// Extend the hunk for as long as the next change is within reach of the trailing context.
+		for j := i + 1; j < len(ops); j++ {
+			if ops[j].kind != opEqual {
+				end = j + 1
+			} else if j-end >= 2*context {
+				break
+			}
+		}
+		i = end - 1
+
+		if end += context; end > len(ops) {
+			end = len(ops)
+		}
+		// Merge with the previous hunk if overlapping.
+		if l := len(hs); l != 0 && hs[l-1][1] >= start {
+			hs[l-1][1] = end
+			continue
+		}
+		hs = append(hs, [2]int{start, end})
+	}
+	return hs
+}
+
+func writeHunk(sb *strings.Builder, ops []op) {
+	aStart, bStart, aCount, bCount := ops[0].a, ops[0].b, 0, 0
+	for _, o := range ops {
+		switch o.kind {
+		case opEqual:
+			aCount, bCount = aCount+1, bCount+1
+		case opDelete:
+			aCount++
+		case opInsert:
+			bCount++
+		}
+	}
+	sb.WriteString(fmt.Sprintf("@@ -%s +%s @@\n", hunkRange(aStart, aCount), hunkRange(bStart, bCount)))
+
+	for _, o := range ops {
+		prefix := " "
+		switch o.kind {
+		case opDelete:
+			prefix = "-"
+		case opInsert:
+			prefix = "+"
+		}
+		sb.WriteString(prefix + strings.TrimSuffix(o.line, "\n") + "\n")
+		if !strings.HasSuffix(o.line, "\n") {
+			sb.WriteString("\\ No newline at end of file\n")
+		}
+	}
+}
+
+// hunkRange formats a hunk range as per the unified format: line numbers are 1-based, and an empty range refers to
+// the line preceding it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start+1)
+	}
+	return fmt.Sprintf("%d,%d", start+1, count)
+}
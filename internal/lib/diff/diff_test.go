@@ -0,0 +1,124 @@
+package diff
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		a, b     string
+		expected string
+	}{
+		{
+			name:     "identical",
+			a:        "a\nb\n",
+			b:        "a\nb\n",
+			expected: "",
+		},
+		{
+			name:     "modified line",
+			a:        "a\nb\nc\n",
+			b:        "a\nx\nc\n",
+			expected: "--- a/f\n+++ b/f\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name:     "created file",
+			a:        "",
+			b:        "a\n",
+			expected: "--- a/f\n+++ b/f\n@@ -0,0 +1 @@\n+a\n",
+		},
+		{
+			name:     "missing newline",
+			a:        "a\nb",
+			b:        "a\nc",
+			expected: "--- a/f\n+++ b/f\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+c\n\\ No newline at end of file\n",
+		},
+		{
+			name: "distant changes",
+			a:    "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			b:    "0\n2\n3\n4\n5\n6\n7\n8\n9\n0\n",
+			expected: "--- a/f\n+++ b/f\n@@ -1,4 +1,4 @@\n-1\n+0\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+0\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := Unified("a/f", "b/f", []byte(tt.a), []byte(tt.b), DefaultContext); result != tt.expected {
+				t.Errorf("Expected:\n%s\nbut got:\n%s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	// lcs returns the length of the longest common subsequence of `a` and `b`; a shortest edit script has
+	// len(a)+len(b)-2*lcs changes.
+	lcs := func(a, b []string) int {
+		dp := make([][]int, len(a)+1)
+		for i := range dp {
+			dp[i] = make([]int, len(b)+1)
+		}
+		for i := len(a) - 1; i >= 0; i-- {
+			for j := len(b) - 1; j >= 0; j-- {
+				if a[i] == b[j] {
+					dp[i][j] = dp[i+1][j+1] + 1
+				} else if dp[i+1][j] > dp[i][j+1] {
+					dp[i][j] = dp[i+1][j]
+				} else {
+					dp[i][j] = dp[i][j+1]
+				}
+			}
+		}
+		return dp[0][0]
+	}
+	lines := func(r *rand.Rand, n int) []string {
+		ls := make([]string, n)
+		for i := range ls {
+			ls[i] = string(rune('a'+r.Intn(3))) + "\n"
+		}
+		return ls
+	}
+
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		a, b := lines(r, r.Intn(12)), lines(r, r.Intn(12))
+		ops := compute(a, b)
+
+		var ra, rb []string
+		changes := 0
+		for _, o := range ops {
+			switch o.kind {
+			case opEqual:
+				ra, rb = append(ra, o.line), append(rb, o.line)
+			case opDelete:
+				ra, changes = append(ra, o.line), changes+1
+			case opInsert:
+				rb, changes = append(rb, o.line), changes+1
+			}
+			if (o.kind != opInsert && a[o.a] != o.line) || (o.kind != opDelete && b[o.b] != o.line) {
+				t.Fatalf("%q => %q: operation %+v does not match its position", a, b, o)
+			}
+		}
+		if strings.Join(ra, "") != strings.Join(a, "") || strings.Join(rb, "") != strings.Join(b, "") {
+			t.Fatalf("%q => %q: edit script does not transform a into b", a, b)
+		}
+		if expected := len(a) + len(b) - 2*lcs(a, b); changes != expected {
+			t.Fatalf("%q => %q: expected %d changes but got %d", a, b, expected, changes)
+		}
+	}
+
+	// -> A rewritten file is the worst case (i.e. D = n+m); it must not retain a trace per step.
+	t.Run("rewritten file", func(t *testing.T) {
+		a, b := make([]string, 5000), make([]string, 5000)
+		for i := range a {
+			a[i], b[i] = fmt.Sprintf("a%d\n", i), fmt.Sprintf("b%d\n", i)
+		}
+		if ops := compute(a, b); len(ops) != len(a)+len(b) {
+			t.Errorf("Expected %d operations but got %d", len(a)+len(b), len(ops))
+		}
+	})
+}
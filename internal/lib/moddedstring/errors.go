@@ -0,0 +1,44 @@
+package moddedstring
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Error represents an invalid token occurrence within a modded string.
+//
+//	'\{pkg.fooBar\}.go' => '\{pkg.fooBar\}' (position 0): token 'pkg': unknown modifier 'fooBar' (allowed: asLower, ...)
+type Error struct {
+	// Src represents the modded string (e.g. '\{pkg.fooBar\}.go').
+	Src string
+	// Expr represents the offending occurrence (e.g. '\{pkg.fooBar\}').
+	Expr string
+	// Pos represents the byte offset of the occurrence within `Src`.
+	Pos int
+	// Token represents the token of the occurrence (e.g. 'pkg', 'vars.module'); empty if it could not be determined.
+	Token string
+	// Modifier represents the offending modifier, if any (e.g. 'fooBar').
+	Modifier string
+	// Allowed represents the values allowed in place of the offending one, if any (i.e. tokens or modifiers).
+	Allowed []string
+
+	msg string
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "'%s' (position %d): ", e.Expr, e.Pos)
+	if e.Token != "" {
+		fmt.Fprintf(&sb, "token '%s': ", e.Token)
+	}
+	sb.WriteString(e.msg)
+	if len(e.Allowed) != 0 {
+		fmt.Fprintf(&sb, " (allowed: %s)", strings.Join(e.Allowed, ", "))
+	}
+	return sb.String()
+}
+
+// exprError returns an error pertaining to the given expression; its location is set by the caller (see `parse`).
+func exprError(token, format string, args ...any) *Error {
+	return &Error{Token: token, msg: fmt.Sprintf(format, args...)}
+}
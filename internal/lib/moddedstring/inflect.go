@@ -0,0 +1,177 @@
+package moddedstring
+
+import (
+	"strings"
+	"unicode"
+)
+
+type inflection int
+
+const (
+	plural inflection = iota
+	singular
+)
+
+var (
+	// irregulars represents the nouns whose plural does not follow the rules; singular => plural.
+	irregulars = map[string]string{
+		"person": "people",
+		"child":  "children",
+		"man":    "men",
+		"woman":  "women",
+		"mouse":  "mice",
+		"goose":  "geese",
+		"tooth":  "teeth",
+		"foot":   "feet",
+		"leaf":   "leaves",
+		"life":   "lives",
+		"knife":  "knives",
+		"wife":   "wives",
+		"half":   "halves",
+		"shelf":  "shelves",
+		"index":  "indices",
+		"matrix": "matrices",
+		"vertex": "vertices",
+	}
+	// uncountables represents the nouns whose plural is the same as their singular.
+	uncountables = map[string]bool{
+		"data": true, "metadata": true, "information": true, "equipment": true, "news": true, "money": true,
+		"series": true, "species": true, "sheep": true, "fish": true, "deer": true, "feedback": true, "media": true,
+	}
+	// singularsInIe represents the nouns ending with 'ie', whose plural ('-ies') is not that of a noun ending with 'y'.
+	singularsInIe = map[string]bool{
+		"movie": true, "cookie": true, "tie": true, "pie": true, "lie": true, "zombie": true, "calorie": true,
+		"rookie": true, "selfie": true, "genie": true,
+	}
+	// singularsInChe represents the nouns ending with 'che', whose plural ('-ches') is not that of a noun ending with
+	// 'ch'.
+	singularsInChe = map[string]bool{"cache": true, "niche": true, "ache": true, "avalanche": true, "moustache": true}
+)
+
+// inflect returns the plural or singular form of `s`; only the last word of compound identifiers is inflected (e.g.
+// 'invoice_item' => 'invoice_items', 'InvoiceItem' => 'InvoiceItems'), and its case is preserved.
+func inflect(s string, i inflection) string {
+	start := lastWord(s)
+	word := s[start:]
+	if word == "" {
+		return s
+	}
+
+	lower := strings.ToLower(word)
+	var inflected string
+	if i == plural {
+		inflected = pluralize(lower)
+	} else {
+		inflected = singularize(lower)
+	}
+	return s[:start] + matchCase(word, inflected)
+}
+
+// lastWord returns the index of the last word of `s`; words are separated by non-alphanumeric characters, or by a
+// change of case (e.g. 'InvoiceItem' => 7, 'HTTPRequest' => 4).
+func lastWord(s string) int {
+	r := []rune(s)
+	i := len(r)
+	for ; i > 0; i-- {
+		prev := r[i-1]
+		if !unicode.IsLetter(prev) && !unicode.IsDigit(prev) {
+			break
+		}
+		if i < len(r) {
+			cur := r[i]
+			if unicode.IsLower(prev) && unicode.IsUpper(cur) ||
+				unicode.IsUpper(prev) && unicode.IsUpper(cur) && i+1 < len(r) && unicode.IsLower(r[i+1]) {
+				break
+			}
+		}
+	}
+	return len(string(r[:i]))
+}
+
+func pluralize(w string) string {
+	if uncountables[w] {
+		return w
+	}
+	if p, ok := irregulars[w]; ok {
+		return p
+	}
+	for _, p := range irregulars {
+		if p == w {
+			return w // -> Already plural.
+		}
+	}
+
+	switch {
+	case hasConsonantBefore(w, "y"):
+		return w[:len(w)-1] + "ies"
+	case strings.HasSuffix(w, "s"), strings.HasSuffix(w, "x"), strings.HasSuffix(w, "z"),
+		strings.HasSuffix(w, "ch"), strings.HasSuffix(w, "sh"):
+		return w + "es"
+	default:
+		return w + "s"
+	}
+}
+
+func singularize(w string) string {
+	if uncountables[w] {
+		return w
+	}
+	for s, p := range irregulars {
+		if p == w {
+			return s
+		}
+	}
+	if _, ok := irregulars[w]; ok {
+		return w // -> Already singular.
+	}
+
+	switch {
+	case strings.HasSuffix(w, "ies"):
+		if singularsInIe[w[:len(w)-1]] {
+			return w[:len(w)-1]
+		}
+		return w[:len(w)-3] + "y"
+	case strings.HasSuffix(w, "sses"), strings.HasSuffix(w, "xes"), strings.HasSuffix(w, "zzes"),
+		strings.HasSuffix(w, "shes"):
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ches"):
+		if singularsInChe[w[:len(w)-1]] {
+			return w[:len(w)-1]
+		}
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "uses"):
+		// -> 'statuses' => 'status', yet 'houses' => 'house'.
+		if len(w) <= 4 || strings.ContainsRune("aeiou", rune(w[len(w)-5])) {
+			return w[:len(w)-1]
+		}
+		return w[:len(w)-2]
+	case strings.HasSuffix(w, "ss"), strings.HasSuffix(w, "us"), strings.HasSuffix(w, "is"):
+		return w // -> Already singular (e.g. 'address', 'status', 'analysis').
+	case strings.HasSuffix(w, "s"):
+		return w[:len(w)-1]
+	default:
+		return w
+	}
+}
+
+// hasConsonantBefore returns true if `w` ends with `suffix`, preceded by a consonant (e.g. 'category').
+func hasConsonantBefore(w, suffix string) bool {
+	if !strings.HasSuffix(w, suffix) || len(w) <= len(suffix) {
+		return false
+	}
+	return !strings.ContainsRune("aeiou", rune(w[len(w)-len(suffix)-1]))
+}
+
+// matchCase returns `s` in the case of `like`: upper case, capitalised, or unchanged.
+func matchCase(like, s string) string {
+	switch {
+	case len(like) > 1 && strings.ToUpper(like) == like:
+		return strings.ToUpper(s)
+	case unicode.IsUpper([]rune(like)[0]):
+		r := []rune(s)
+		r[0] = unicode.ToUpper(r[0])
+		return string(r)
+	default:
+		return s
+	}
+}
@@ -2,37 +2,77 @@ package moddedstring
 
 import (
 	"fmt"
-	"github.com/maxzaleski/codegen/internal/lib/slice"
 	"regexp"
+	"strings"
 )
 
-var stringModsRegex = regexp.MustCompile("\\\\{([aA-zZ]+\\.([aA-zZ]+\\.?)+)\\\\}")
+var stringModsRegex = regexp.MustCompile(`\\\{([^{}]*)\\\}`)
 
 // New attempts to parse the given modded string and returns the result.
 //
-// If any of the specified mods are invalid, an error is returned.
+// If any of the specified tokens or mods are invalid, an error is returned.
 func New(src string, tokenMap map[string]string) (string, error) {
-	matches_ := stringModsRegex.FindAllStringSubmatch(src, -1) // form: '{token.mod1.mod2...}'
-	matches := slice.Map(matches_, func(s []string) string { return s[1] })
+	ms, err := parse(src)
+	if err != nil {
+		return "", err
+	}
 
-	ms := &moddedString{}
+	// Applies the string modifiers.
+	if err = ms.ApplyMods(tokenMap); err != nil {
+		return "", err
+	}
+	return ms.String(), nil
+}
 
-	// Parse all modifier instances and assign them to the modded string.
-	for rKey, match := range matches {
-		if ok := ms.AssignMod(rKey, match); !ok {
-			return "", nil
+// References returns true if the given modded string refers to `token` (e.g. 'model'); it is assumed to be valid.
+func References(src, token string) bool {
+	ms, _ := parse(src)
+	if ms == nil {
+		return false
+	}
+	for _, e := range ms.Exprs {
+		if e.Token == token || strings.HasPrefix(e.Token, token+".") {
+			return true
 		}
 	}
+	return false
+}
 
-	// Replace all modifier instances with a replacement key '{pkg.asCamel}' => '{n}'.
-	i := len(matches)
-	ms.Value = stringModsRegex.ReplaceAllStringFunc(src, func(s string) string {
-		defer func(i *int) { *i-- }(&i)
-		return fmt.Sprintf("{%d}", len(matches)-i)
-	})
+// Mask replaces the token occurrences of `src` with 'x', as for its literal parts to be validated (e.g.
+// '\{pkg.asSnake\}_test.go' => 'x_test.go').
+func Mask(src string) string {
+	return stringModsRegex.ReplaceAllString(src, "x")
+}
 
-	// Applies the string modifiers.
-	ms.ApplyMods(tokenMap)
+// parse parses the token occurrences of `src` (form: '\{token.mod1.mod2...\}'), replacing each with a replacement
+// key (e.g. '\{pkg.asCamel\}' => '{0}').
+//
+// The first invalid occurrence is reported as an `*Error`.
+func parse(src string) (*moddedString, error) {
+	ms := &moddedString{Src: src}
+	for rKey, loc := range stringModsRegex.FindAllStringSubmatchIndex(src, -1) {
+		raw := src[loc[0]:loc[1]]
+		e, err := parseExpr(rKey, src[loc[2]:loc[3]])
+		if err != nil {
+			err.Src, err.Expr, err.Pos = src, raw, loc[0]
+			return nil, err
+		}
+		e.Raw, e.Pos = raw, loc[0]
+		ms.Exprs = append(ms.Exprs, e)
+	}
 
-	return ms.String(), nil
+	// -> Delimiters left once the occurrences are blanked out (preserving offsets) are unterminated.
+	blanked := stringModsRegex.ReplaceAllStringFunc(src, func(m string) string { return strings.Repeat(" ", len(m)) })
+	if pos := strings.Index(blanked, `\{`); pos != -1 {
+		return nil, &Error{Src: src, Expr: src[pos:], Pos: pos, msg: "unterminated token"}
+	} else if pos = strings.Index(blanked, `\}`); pos != -1 {
+		return nil, &Error{Src: src, Expr: src[:pos+2], Pos: pos, msg: "unmatched token delimiter"}
+	}
+
+	i := -1
+	ms.Value = stringModsRegex.ReplaceAllStringFunc(src, func(string) string {
+		i++
+		return fmt.Sprintf("{%d}", i)
+	})
+	return ms, nil
 }
@@ -0,0 +1,134 @@
+package moddedstring
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNew(t *testing.T) {
+	tokenMap := map[string]string{
+		"pkg":      "invoice_item",
+		"pkg.path": "billing/invoice_item",
+		"vars.v2":  "api_version",
+		"scope":    "domain",
+		"model":    "Category",
+	}
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{src: "models.go", expected: "models.go"},
+		{src: "\\{pkg.asTitle\\}.java", expected: "InvoiceItem.java"},
+		{src: "\\{pkg.asKebab\\}_controller.ts", expected: "invoice-item_controller.ts"},
+		{src: "\\{pkg.path\\}.go", expected: "billing/invoice_item.go"},
+		{src: "\\{pkg.path.asUpper.asSnake\\}.go", expected: "BILLING/INVOICE_ITEM.go"},
+		{src: "\\{vars.v2.asKebab\\}.go", expected: "api-version.go"},
+		{src: "\\{pkg\\}.go", expected: "invoice_item.go"},
+		{src: "\\{pkg.asUpper\\}.go", expected: "INVOICE_ITEM.go"},
+		{src: "\\{pkg.plural.asTitle\\}.java", expected: "InvoiceItems.java"},
+		{src: "\\{pkg.trimPrefix(invoice_).asCamel\\}.ts", expected: "Item.ts"},
+		{src: "\\{pkg.path.trimSuffix(_item).asDot\\}.go", expected: "billing.invoice.go"},
+		{src: "\\{scope\\}/\\{model.plural.asSnake\\}.go", expected: "domain/categories.go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			result, err := New(tt.src, tokenMap)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result != tt.expected {
+				t.Errorf("Expected %s but got %s", tt.expected, result)
+			}
+		})
+	}
+}
+
+func TestNew_UndefinedVar(t *testing.T) {
+	if _, err := New("\\{vars.module\\}.go", map[string]string{}); err == nil {
+		t.Error("Expected an error for an undefined variable")
+	}
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected Error
+	}{
+		{
+			src:      "user_\\{pkg.fooBar\\}.go",
+			expected: Error{Expr: "\\{pkg.fooBar\\}", Pos: 5, Token: "pkg", Modifier: "fooBar", Allowed: Modifiers()},
+		},
+		{
+			src:      "\\{pkg\\}_\\{foo.asSnake\\}.go",
+			expected: Error{Expr: "\\{foo.asSnake\\}", Pos: 8, Allowed: Tokens},
+		},
+		{
+			src:      "\\{pkg.trimPrefix\\}.go",
+			expected: Error{Expr: "\\{pkg.trimPrefix\\}", Token: "pkg", Modifier: "trimPrefix"},
+		},
+		{
+			src:      "\\{vars.module\\}/\\{vars.missing\\}.go",
+			expected: Error{Expr: "\\{vars.missing\\}", Pos: 16, Token: "vars.missing"},
+		},
+		{
+			src:      "models_\\{pkg.go",
+			expected: Error{Expr: "\\{pkg.go", Pos: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			_, err := New(tt.src, map[string]string{"pkg": "user", "vars.module": "app"})
+			var e *Error
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected an *Error but got %v", err)
+			}
+			tt.expected.Src, tt.expected.msg = tt.src, e.msg
+			if !reflect.DeepEqual(*e, tt.expected) {
+				t.Errorf("Expected %+v but got %+v", tt.expected, *e)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		mods     []string
+		expected bool
+	}{
+		{mods: []string{"asLower"}, expected: true},
+		{mods: []string{"path"}, expected: true},
+		{mods: []string{"path", "asSnake"}, expected: true},
+		{mods: []string{"asSnake", "path"}, expected: false},
+		{mods: []string{"fooBar"}, expected: false},
+		{mods: []string{"plural", "trimPrefix(Base)", "asDot"}, expected: true},
+		{mods: []string{"trimSuffix"}, expected: false},
+		{mods: []string{"trimSuffix()"}, expected: false},
+		{mods: []string{"asSnake(x)"}, expected: false},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.mods, "."), func(t *testing.T) {
+			src := "\\{" + strings.Join(append([]string{"pkg"}, tt.mods...), ".") + "\\}.go"
+			if result := Validate(src) == nil; result != tt.expected {
+				t.Errorf("Expected %v but got %v", tt.expected, result)
+			}
+		})
+	}
+
+	t.Run("tokens", func(t *testing.T) {
+		for src, expected := range map[string]bool{
+			"\\{scope\\}_\\{job.asKebab\\}.go": true,
+			"\\{model.singular\\}.java":        true,
+			"\\{domain\\}.go":                  true,
+			"\\{vars.module.asPath\\}.go":      true,
+			"\\{vars\\}.go":                    false,
+			"\\{foo.asSnake\\}.go":             false,
+			"\\{pkg.asSnake.go":                false,
+		} {
+			if result := Validate(src) == nil; result != expected {
+				t.Errorf("Expected %v but got %v for '%s'", expected, result, src)
+			}
+		}
+	})
+}
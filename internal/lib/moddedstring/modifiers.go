@@ -1,6 +1,10 @@
 package moddedstring
 
-import "github.com/iancoleman/strcase"
+import (
+	"strings"
+
+	"github.com/iancoleman/strcase"
+)
 
 const (
 	CaseModifierNone  CaseModifier = ""
@@ -13,8 +17,98 @@ const (
 	CaseModifierKebab CaseModifier = "asKebab"
 )
 
+const (
+	ModifierPlural     = "plural"
+	ModifierSingular   = "singular"
+	ModifierTrimPrefix = "trimPrefix"
+	ModifierTrimSuffix = "trimSuffix"
+	ModifierAsDot      = "asDot"
+	ModifierAsPath     = "asPath"
+)
+
+// transforms represents the modifiers other than case modifiers; `arg` indicates whether the modifier expects an
+// argument (e.g. 'trimPrefix(Base)').
+var transforms = []struct {
+	name  string
+	arg   bool
+	apply func(s, arg string) string
+}{
+	{ModifierPlural, false, func(s, _ string) string { return inflect(s, plural) }},
+	{ModifierSingular, false, func(s, _ string) string { return inflect(s, singular) }},
+	{ModifierTrimPrefix, true, strings.TrimPrefix},
+	{ModifierTrimSuffix, true, strings.TrimSuffix},
+	// -> 'billing/invoice' => 'billing.invoice'
+	{ModifierAsDot, false, func(s, _ string) string { return strings.ReplaceAll(s, "/", ".") }},
+	// -> 'com.example.app' => 'com/example/app'
+	{ModifierAsPath, false, func(s, _ string) string { return strings.ReplaceAll(s, ".", "/") }},
+}
+
+// Modifiers returns the names of all modifiers.
+func Modifiers() []string {
+	names := []string{
+		string(CaseModifierLower), string(CaseModifierUpper), string(CaseModifierTitle),
+		string(CaseModifierSnake), string(CaseModifierCamel), string(CaseModifierKebab),
+	}
+	for _, t := range transforms {
+		names = append(names, t.name)
+	}
+	return names
+}
+
+func isModifier(name string) bool {
+	if name != "" && CaseModifier(name).IsValid() {
+		return true
+	}
+	for _, t := range transforms {
+		if t.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func takesArg(name string) bool {
+	for _, t := range transforms {
+		if t.name == name {
+			return t.arg
+		}
+	}
+	return false
+}
+
+// applyModifiers applies the given modifiers to `token`, from left to right; consecutive case modifiers are combined
+// (e.g. 'asUpper.asSnake' => 'HELLO_WORLD').
+func applyModifiers(token string, mods []Modifier) string {
+	for i := 0; i < len(mods); i++ {
+		if !CaseModifier(mods[i].Name).IsValid() {
+			for _, t := range transforms {
+				if t.name == mods[i].Name {
+					token = t.apply(token, mods[i].Arg)
+				}
+			}
+			continue
+		}
+
+		pm, sm := CaseModifierNone, CaseModifierNone
+		for ; i < len(mods) && CaseModifier(mods[i].Name).IsValid(); i++ {
+			if m := CaseModifier(mods[i].Name); PrimaryCaseModifier(m).IsValid() {
+				pm = m
+			} else {
+				sm = m
+			}
+		}
+		i-- // -> The outer loop increments `i`.
+		token = applyCaseModifiers(token, pm, sm)
+	}
+	return token
+}
+
 func applyCaseModifiers(token string, pm CaseModifier, sm CaseModifier) (result string) {
-	if pm == CaseModifierLower && sm == CaseModifierCamel {
+	if pm == CaseModifierLower && sm == CaseModifierNone {
+		result = strings.ToLower(token) // hello_world
+	} else if pm == CaseModifierUpper && sm == CaseModifierNone {
+		result = strings.ToUpper(token) // HELLO_WORLD
+	} else if pm == CaseModifierLower && sm == CaseModifierCamel {
 		result = strcase.ToLowerCamel(token) // helloWorld
 	} else if pm == CaseModifierUpper && sm == CaseModifierSnake {
 		result = strcase.ToScreamingSnake(token) // HELLO_WORLD
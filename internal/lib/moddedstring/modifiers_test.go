@@ -76,3 +76,59 @@ func TestApplyCaseModifiers(t *testing.T) {
 		}
 	}
 }
+
+func TestApplyModifiers(t *testing.T) {
+	tests := []struct {
+		token    string
+		mods     []Modifier
+		expected string
+	}{
+		{"hello_world", []Modifier{{Name: "asUpper"}, {Name: "asSnake"}}, "HELLO_WORLD"},
+		{"com.example.app", []Modifier{{Name: "asPath"}}, "com/example/app"},
+		{"BaseModel", []Modifier{{Name: "trimPrefix", Arg: "Base"}, {Name: "plural"}, {Name: "asSnake"}}, "models"},
+		{"billing/invoice", []Modifier{{Name: "asDot"}, {Name: "asUpper"}}, "BILLING.INVOICE"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expected, func(t *testing.T) {
+			if result := applyModifiers(test.token, test.mods); result != test.expected {
+				t.Errorf("Expected '%s' but got '%s'", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestInflect(t *testing.T) {
+	tests := []struct {
+		singular string
+		plural   string
+	}{
+		{"user", "users"},
+		{"category", "categories"},
+		{"key", "keys"},
+		{"address", "addresses"},
+		{"status", "statuses"},
+		{"box", "boxes"},
+		{"branch", "branches"},
+		{"cache", "caches"},
+		{"house", "houses"},
+		{"movie", "movies"},
+		{"person", "people"},
+		{"metadata", "metadata"},
+		{"InvoiceItem", "InvoiceItems"},
+		{"HTTPRequest", "HTTPRequests"},
+		{"line_entry", "line_entries"},
+		{"USER", "USERS"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.singular, func(t *testing.T) {
+			if result := inflect(test.singular, plural); result != test.plural {
+				t.Errorf("Expected plural '%s' but got '%s'", test.plural, result)
+			}
+			if result := inflect(test.plural, singular); result != test.singular {
+				t.Errorf("Expected singular '%s' but got '%s'", test.singular, result)
+			}
+		})
+	}
+}
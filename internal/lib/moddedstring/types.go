@@ -2,44 +2,154 @@ package moddedstring
 
 import (
 	"fmt"
+	"regexp"
 	"strings"
 )
 
 type (
 	moddedString struct {
+		// Src represents the string as declared (e.g. '\{pkg.asSnake\}.go').
+		Src   string
 		Value string
-		Mods  []*stringModifier
+		Exprs []*expression
 	}
 
-	stringModifier struct {
+	// expression represents an occurrence of a token within a modded string (e.g. '\{pkg.path.asSnake\}').
+	expression struct {
 		ReplacementKey int
-		Token          string
-		Mods           []CaseModifier
+		// Raw represents the occurrence as declared (e.g. '\{pkg.asSnake\}'), and Pos its byte offset within the string.
+		Raw   string
+		Pos   int
+		Token string
+		Mods  []Modifier
+	}
+
+	// Modifier represents a modifier applied to a token, and its argument, if any (e.g. 'trimPrefix(Base)').
+	Modifier struct {
+		Name string
+		Arg  string
 	}
 )
 
-// AssignMod assigns a string modifier to the modded string.
-//
-// - `rKey` is the replacement key, which is the index of the string modifier in the modded string (e.g. 0 => '{0}').
-//
-// - `match` is the string modifier to assign (e.g. 'asCamel').
-func (s *moddedString) AssignMod(rKey int, match string) bool {
-	vals := strings.Split(match, ".")
-
-	sm := &stringModifier{
-		ReplacementKey: rKey, // {...asLower...} => {n}, where n is the replacement key
-		Token:          vals[0],
-		Mods:           make([]CaseModifier, len(vals)-1),
+const (
+	TokenPkg    = "pkg"
+	TokenScope  = "scope"
+	TokenJob    = "job"
+	TokenModel  = "model"
+	TokenDomain = "domain"
+	TokenVars   = "vars"
+
+	// TokenPkgPath represents the path of the package (see `properties`).
+	TokenPkgPath = TokenPkg + "." + propertyPath
+)
+
+// Tokens represents the tokens a modded string may refer to.
+var Tokens = []string{TokenPkg, TokenScope, TokenJob, TokenModel, TokenDomain, TokenVars}
+
+const propertyPath = "path"
+
+var (
+	// properties represents the token properties (e.g. 'pkg.path'); their values are provided via the token map under
+	// the qualified key.
+	properties = map[string][]string{TokenPkg: {propertyPath}}
+
+	// qualifiedTokens represents the tokens qualified by a name (e.g. 'vars.module'); their values are provided via the
+	// token map under the qualified key, which must be present.
+	qualifiedTokens = map[string]bool{TokenVars: true}
+
+	nameRegex     = regexp.MustCompile("^[A-Za-z_][A-Za-z0-9_]*$")
+	modifierRegex = regexp.MustCompile(`^([A-Za-z]+)(?:\((.*)\))?$`)
+)
+
+// parseExpr parses the content of a token occurrence (e.g. 'pkg.path.trimPrefix(x).asSnake'); the location of the
+// returned error is left to the caller.
+func parseExpr(rKey int, src string) (*expression, *Error) {
+	segs := splitExpr(src)
+	e := &expression{ReplacementKey: rKey, Token: segs[0]}
+	if !isToken(e.Token) {
+		err := exprError("", "unknown token '%s'", e.Token)
+		err.Allowed = Tokens
+		return nil, err
+	}
+	segs = segs[1:]
+
+	// -> A property may qualify the token (e.g. 'pkg.path'), as may a name (e.g. 'vars.module').
+	if qualifiedTokens[e.Token] {
+		if len(segs) == 0 || !nameRegex.MatchString(segs[0]) {
+			return nil, exprError(e.Token, "expects a name (e.g. '%s.module')", e.Token)
+		}
+		e.Token, segs = e.Token+"."+segs[0], segs[1:]
+	} else if len(segs) != 0 && isProperty(e.Token, segs[0]) {
+		e.Token, segs = e.Token+"."+segs[0], segs[1:]
+	}
+
+	for _, seg := range segs {
+		m, err := parseModifier(seg)
+		if err != nil {
+			err.Token = e.Token
+			return nil, err
+		}
+		e.Mods = append(e.Mods, m)
+	}
+	return e, nil
+}
+
+// splitExpr splits the given expression by dots, ignoring those within the argument of a modifier.
+func splitExpr(src string) []string {
+	segs, depth, start := make([]string, 0), 0, 0
+	for i, r := range src {
+		switch r {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case '.':
+			if depth == 0 {
+				segs, start = append(segs, src[start:i]), i+1
+			}
+		}
+	}
+	return append(segs, src[start:])
+}
+
+func parseModifier(seg string) (Modifier, *Error) {
+	sm := modifierRegex.FindStringSubmatch(seg)
+	if sm == nil || !isModifier(sm[1]) {
+		err := exprError("", "unknown modifier '%s'", seg)
+		err.Modifier, err.Allowed = seg, Modifiers()
+		return Modifier{}, err
 	}
-	for _, val := range vals[1:] {
-		if !PrimaryCaseModifier(val).IsValid() && !SecondaryCaseModifier(val).IsValid() {
-			return false
+	m, hasArg := Modifier{Name: sm[1], Arg: sm[2]}, strings.HasSuffix(seg, ")")
+	if takesArg(m.Name) {
+		if m.Arg == "" {
+			err := exprError("", "modifier '%s' expects an argument (e.g. '%s(Base)')", m.Name, m.Name)
+			err.Modifier = m.Name
+			return Modifier{}, err
 		}
-		sm.Mods = append(sm.Mods, CaseModifier(val))
+	} else if hasArg {
+		err := exprError("", "modifier '%s' does not take an argument", m.Name)
+		err.Modifier = m.Name
+		return Modifier{}, err
 	}
-	s.Mods = append(s.Mods, sm)
+	return m, nil
+}
 
-	return true
+func isToken(token string) bool {
+	for _, t := range Tokens {
+		if t == token {
+			return true
+		}
+	}
+	return false
+}
+
+func isProperty(token, val string) bool {
+	for _, p := range properties[token] {
+		if p == val {
+			return true
+		}
+	}
+	return false
 }
 
 func (s *moddedString) String() string {
@@ -47,23 +157,25 @@ func (s *moddedString) String() string {
 }
 
 // ApplyMods applies the string modifiers to the string.
-func (s *moddedString) ApplyMods(tokenMap map[string]string) {
-	for _, mod := range s.Mods {
-		token, pm, sm := mod.Token, CaseModifierNone, CaseModifierNone
-		if v := tokenMap[token]; v != "" {
-			token = v
-		}
-		for _, m := range mod.Mods {
-			if PrimaryCaseModifier(m).IsValid() {
-				pm = m
-			} else if SecondaryCaseModifier(m).IsValid() {
-				sm = m
+//
+// Tokens absent from the token map are replaced by their name (e.g. 'pkg' for unique jobs), unless qualified (e.g. an
+// undefined variable), in which case an error is returned.
+func (s *moddedString) ApplyMods(tokenMap map[string]string) error {
+	for _, e := range s.Exprs {
+		v, ok := tokenMap[e.Token]
+		if !ok {
+			if q := strings.SplitN(e.Token, ".", 2); qualifiedTokens[q[0]] {
+				err := exprError(e.Token, "not defined")
+				err.Src, err.Expr, err.Pos = s.Src, e.Raw, e.Pos
+				return err
 			}
+			v = e.Token
 		}
 		s.Value = strings.Replace(s.Value,
-			/* old */ fmt.Sprintf("{%d}", mod.ReplacementKey),
-			/* new */ applyCaseModifiers(token, pm, sm),
+			/* old */ fmt.Sprintf("{%d}", e.ReplacementKey),
+			/* new */ applyModifiers(v, e.Mods),
 			1,
 		)
 	}
+	return nil
 }
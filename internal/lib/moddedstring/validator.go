@@ -1,15 +1,11 @@
 package moddedstring
 
-// Validate validates the given modifier tokens.
+// Validate validates the tokens and modifiers of the given modded string; the first invalid one is reported.
 //
-//	mods := ["asLower", "fooBar"]
-//	mods[1] => valid
-//	mods[2] => invalid
-func Validate(mods []string) bool {
-	for _, t := range mods {
-		if !CaseModifier(t).IsValid() {
-			return false
-		}
-	}
-	return true
+//	'\{pkg.path.asSnake\}.go'  => valid
+//	'\{pkg.fooBar\}.go'        => invalid: unknown modifier 'fooBar'
+//	'\{pkg.asSnake.path\}.go'  => invalid: properties precede modifiers
+func Validate(src string) error {
+	_, err := parse(src)
+	return err
 }
@@ -0,0 +1,171 @@
+package scaffold
+
+import (
+	"bytes"
+	"embed"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/fs"
+	"github.com/pkg/errors"
+)
+
+//go:embed presets
+var presetsFS embed.FS
+
+// Preset represents a set of starter templates and packages targeting a language.
+type Preset string
+
+const (
+	PresetGo         Preset = "go"
+	PresetTypeScript Preset = "typescript"
+	PresetJava       Preset = "java"
+)
+
+// Presets returns the available presets.
+func Presets() []Preset {
+	return []Preset{PresetGo, PresetTypeScript, PresetJava}
+}
+
+func (p Preset) IsValid() bool {
+	switch p {
+	case PresetGo,
+		PresetTypeScript,
+		PresetJava:
+		return true
+	default:
+		return false
+	}
+}
+
+// configData represents the values of the configuration template ('presets/config.yaml.tmpl').
+type configData struct {
+	// Represents the location of the '.codegen' directory, relative to the working directory.
+	Dir string
+	// Represents the output directories of the 'pkg' and 'http' scopes.
+	PkgOutput, HttpOutput string
+	// Represents the file names of the jobs; the 'methods' job is omitted if `MethodsFile` is empty.
+	ModelFile, MethodsFile, ServiceFile, RoutesFile string
+}
+
+func (p Preset) configData() configData {
+	switch p {
+	case PresetTypeScript:
+		return configData{
+			PkgOutput:   "src/domain",
+			HttpOutput:  "src/routes",
+			ModelFile:   "model.ts",
+			ServiceFile: "service.ts",
+			RoutesFile:  "routes.ts",
+		}
+	case PresetJava:
+		return configData{
+			PkgOutput:   "src/main/java/domain",
+			HttpOutput:  "src/main/java/routes",
+			ModelFile:   "Models.java",
+			ServiceFile: "Service.java",
+			RoutesFile:  "Routes.java",
+		}
+	default:
+		return configData{
+			PkgOutput:   "internal/domain",
+			HttpOutput:  "internal/routes",
+			ModelFile:   "model.go",
+			MethodsFile: "methods.go",
+			ServiceFile: "service.go",
+			RoutesFile:  "routes.go",
+		}
+	}
+}
+
+// Write writes the '.codegen' directory of the given preset under `location` (relative to `cwd`): a commented
+// 'config.yaml', a sample package, and the starter templates referenced by the configuration.
+//
+// If any of the files already exists, nothing is written unless `force` is set. Returns the paths of the written
+// files, relative to `cwd`.
+func Write(cwd, location string, p Preset, force bool) ([]string, error) {
+	if !p.IsValid() {
+		return nil, errors.Errorf("unknown preset '%s'", p)
+	}
+
+	dir := core.DomainDir
+	if location != "" {
+		dir = filepath.ToSlash(filepath.Join(location, core.DomainDir))
+	}
+	files, err := p.files(dir)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(files))
+	for path := range files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	// [1] Refuse to overwrite existing files, as to not write a partial skeleton.
+	if !force {
+		existing := make([]string, 0)
+		for _, path := range paths {
+			if fs.FileExists(filepath.Join(cwd, path)) {
+				existing = append(existing, path)
+			}
+		}
+		if len(existing) != 0 {
+			return nil, errors.Errorf("refusing to overwrite existing files: %s", strings.Join(existing, ", "))
+		}
+	}
+
+	// [2] Write files.
+	for _, path := range paths {
+		dest := filepath.Join(cwd, path)
+		if _, err = fs.CreateDirINE(filepath.Dir(dest)); err != nil {
+			return nil, err
+		}
+		if err = os.WriteFile(dest, files[path], 0644); err != nil {
+			return nil, errors.Wrapf(err, "failed to write file at '%s'", dest)
+		}
+	}
+	return paths, nil
+}
+
+// files returns the contents of the preset's files, keyed by their path relative to the working directory.
+func (p Preset) files(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+
+	// -> Configuration; shared by all presets.
+	cd := p.configData()
+	cd.Dir = dir
+
+	tt, err := template.ParseFS(presetsFS, "presets/config.yaml.tmpl")
+	if err != nil {
+		panic("binary corrupted")
+	}
+	var buf bytes.Buffer
+	if err = tt.Execute(&buf, cd); err != nil {
+		return nil, errors.Wrap(err, "failed to execute configuration template")
+	}
+	files[dir+"/config.yaml"] = buf.Bytes()
+
+	// -> Packages and templates; copied as is.
+	root := "presets/" + string(p)
+	err = iofs.WalkDir(presetsFS, root, func(path string, d iofs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		b, err := presetsFS.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		files[dir+strings.TrimPrefix(path, root)] = b
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read preset '%s'", p)
+	}
+	return files, nil
+}
@@ -0,0 +1,117 @@
+package scaffold
+
+import (
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/pkg/gen"
+)
+
+func TestWrite(t *testing.T) {
+	tests := []struct {
+		preset   Preset
+		location string
+		expected []string
+	}{
+		{
+			preset: PresetGo,
+			expected: []string{
+				"internal/domain/user/methods.go",
+				"internal/domain/user/model.go",
+				"internal/domain/user/service.go",
+				"internal/routes/routes.go",
+			},
+		},
+		{
+			preset:   PresetTypeScript,
+			location: "web",
+			expected: []string{
+				"src/domain/user/model.ts",
+				"src/domain/user/service.ts",
+				"src/routes/routes.ts",
+			},
+		},
+		{
+			preset: PresetJava,
+			expected: []string{
+				"src/main/java/domain/user/Models.java",
+				"src/main/java/domain/user/Service.java",
+				"src/main/java/routes/Routes.java",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.preset), func(t *testing.T) {
+			reset := setupTestDir(t)
+			defer reset()
+
+			cwd, _ := os.Getwd()
+			paths, err := Write(cwd, tt.location, tt.preset, false)
+			assert.Equal(t, err, nil)
+			assert.NotEqual(t, len(paths), 0)
+
+			// The skeleton must produce a working generation.
+			res, err := gen.Execute(gen.Config{Location: tt.location, WorkerCount: 2}, time.Now())
+			if err != nil {
+				t.Fatalf("failed to execute generation: %+v", err)
+			}
+			for _, path := range tt.expected {
+				if _, err = os.Stat(filepath.Join(res.Metadata.Cwd, path)); err != nil {
+					t.Errorf("expected file '%s' to be generated", path)
+				}
+				if strings.HasSuffix(path, ".go") {
+					_, err = parser.ParseFile(token.NewFileSet(), filepath.Join(res.Metadata.Cwd, path), nil, 0)
+					assert.Equal(t, err, nil)
+				}
+			}
+		})
+	}
+
+	t.Run("refuses to overwrite existing files", func(t *testing.T) {
+		reset := setupTestDir(t)
+		defer reset()
+
+		cwd, _ := os.Getwd()
+		_, err := Write(cwd, "", PresetGo, false)
+		assert.Equal(t, err, nil)
+
+		config := filepath.Join(cwd, ".codegen/config.yaml")
+		if err = os.WriteFile(config, []byte("edited"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		_, err = Write(cwd, "", PresetJava, false)
+		assert.NotEqual(t, err, nil)
+
+		// Nothing was written.
+		b, _ := os.ReadFile(config)
+		assert.Equal(t, string(b), "edited")
+
+		_, err = Write(cwd, "", PresetJava, true)
+		assert.Equal(t, err, nil)
+		b, _ = os.ReadFile(config)
+		assert.NotEqual(t, string(b), "edited")
+	})
+
+	t.Run("unknown preset", func(t *testing.T) {
+		_, err := Write(os.TempDir(), "", "rust", false)
+		assert.NotEqual(t, err, nil)
+	})
+}
+
+// setupTestDir changes the working directory to a temporary one, as template paths are resolved against it.
+func setupTestDir(t *testing.T) func() {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	return func() { _ = os.Chdir(wd) }
+}
@@ -2,6 +2,7 @@ package slog
 
 import (
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"sync"
@@ -23,21 +24,32 @@ type (
 	logger struct {
 		mu *sync.Mutex
 
+		out       *log.Logger
 		debugFlag bool
 		began     time.Time
 	}
 )
 
-// New creates a new logger.
+// New creates a new logger writing to the standard logger.
 func New(debugFlag bool, began time.Time) ILogger {
+	log.SetFlags(0) // Disable datetime prefix.
+
+	return newLogger(log.Default(), debugFlag, began)
+}
+
+// NewWithOutput creates a new logger writing to `w`; the standard logger is left untouched.
+func NewWithOutput(w io.Writer, debugFlag bool, began time.Time) ILogger {
+	return newLogger(log.New(w, "", 0), debugFlag, began)
+}
+
+func newLogger(out *log.Logger, debugFlag bool, began time.Time) ILogger {
 	if os.Getenv("ENV") == "production" && began.IsZero() {
 		panic("logger: `began` cannot be zero")
 	}
 
-	log.SetFlags(0) // Disable datetime prefix.
-
 	l := &logger{
 		mu:        &sync.Mutex{},
+		out:       out,
 		debugFlag: debugFlag,
 		began:     began,
 	}
@@ -54,7 +66,7 @@ func (l *logger) Log(lines ...any) {
 		linesCopy := make([]interface{}, 1, len(lines)+1)
 		linesCopy[0] = domain(LightYellow, "start", "+"+time.Since(l.began).String())
 		linesCopy = append(linesCopy, lines...)
-		log.Println(linesCopy...) // The 'log' package is thread-safe.
+		l.out.Println(linesCopy...) // `log.Logger` is thread-safe.
 	}
 }
 
@@ -0,0 +1,124 @@
+// Package codegen exposes the code generator as a library, as to embed it within other binaries.
+//
+//	g := codegen.New(
+//		codegen.WithLocation("api"),
+//		codegen.WithFuncMap(template.FuncMap{
+//			"add": func(a, b int) int { return a + b },
+//		}),
+//	)
+//	res, err := g.Generate(ctx)
+//	if err != nil {
+//		return err
+//	}
+//	for _, f := range res.Files {
+//		fmt.Println(f.Outcome, f.Path)
+//	}
+package codegen
+
+import (
+	"context"
+	"io"
+	"text/template"
+	"time"
+
+	"github.com/maxzaleski/codegen/pkg/gen"
+	"github.com/maxzaleski/codegen/pkg/vfs"
+)
+
+type (
+	// Generator executes the jobs defined in a '.codegen' directory.
+	Generator struct {
+		config gen.Config
+	}
+
+	// Option configures a `Generator`.
+	Option func(g *Generator)
+)
+
+// DefaultWorkerCount is the number of workers used unless specified otherwise (see `WithWorkerCount`).
+const DefaultWorkerCount = 30
+
+// New returns a new `Generator` configured with the given options.
+func New(opts ...Option) *Generator {
+	g := &Generator{
+		config: gen.Config{
+			WorkerCount: DefaultWorkerCount,
+			LogOutput:   io.Discard,
+		},
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	return g
+}
+
+// WithFuncMap sets the functions that can be called from templates.
+func WithFuncMap(fm template.FuncMap) Option {
+	return func(g *Generator) { g.config.TemplateFuncMap = fm }
+}
+
+// WithLogger writes debug logs to `w`; nothing is logged by default.
+func WithLogger(w io.Writer) Option {
+	return func(g *Generator) {
+		if w == nil {
+			w = io.Discard
+		}
+		g.config.DebugMode, g.config.LogOutput = w != io.Discard, w
+	}
+}
+
+// WithWorkerCount sets the number of jobs executed concurrently; values lower than 1 are ignored.
+func WithWorkerCount(n int) Option {
+	return func(g *Generator) {
+		if n > 0 {
+			g.config.WorkerCount = n
+		}
+	}
+}
+
+// WithLocation sets the location of the directory containing the '.codegen' directory, relative to the working
+// directory; default: the working directory.
+func WithLocation(location string) Option {
+	return func(g *Generator) { g.config.Location = location }
+}
+
+// WithVars sets user-defined variables; they take precedence over the 'vars' declared by the configuration.
+func WithVars(vars map[string]string) Option {
+	return func(g *Generator) { g.config.Vars = vars }
+}
+
+// WithFS sets the filesystem generated files are written to; default: the real disk.
+//
+// Unless backed by the real disk, generations do not advance the baseline used to evaluate `override-on`.
+func WithFS(fsys vfs.FS) Option {
+	return func(g *Generator) { g.config.FS = fsys }
+}
+
+// WithPlan determines what each job would do without writing to disk.
+func WithPlan() Option {
+	return func(g *Generator) { g.config.Plan = true }
+}
+
+// WithCheck compares the output of each job regenerated on every run ('override', 'override-on') against the
+// existing file without writing to disk; see `Result.Drifted`.
+//
+// Files created once, which are meant to be edited, are only expected to exist. Orphaned files are only established
+// if a previous run is recorded locally; see `Result.OrphansChecked`.
+func WithCheck() Option {
+	return func(g *Generator) { g.config.Check = true }
+}
+
+// WithDiff computes the unified diff of each file against its existing counterpart; see `File.Diff`.
+func WithDiff() Option {
+	return func(g *Generator) { g.config.Diff = true }
+}
+
+// Generate executes the jobs until completion, or until the context is cancelled.
+//
+// The result is returned alongside an error, if any; it then reflects the jobs executed prior to the error.
+func (g *Generator) Generate(ctx context.Context) (*Result, error) {
+	began := time.Now()
+
+	res, err := gen.ExecuteContext(ctx, g.config, began)
+	return newResult(res, time.Since(began)), err
+}
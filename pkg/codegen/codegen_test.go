@@ -0,0 +1,277 @@
+package codegen
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/scaffold"
+	"github.com/maxzaleski/codegen/pkg/gen"
+	"github.com/maxzaleski/codegen/pkg/vfs"
+)
+
+func TestGenerator_Generate(t *testing.T) {
+	reset := setupTestDir(t)
+	defer reset()
+
+	ctx, fm := context.Background(), WithFuncMap(template.FuncMap{"shout": strings.ToUpper})
+	routes := "internal/routes/routes.go"
+
+	t.Run("plan", func(t *testing.T) {
+		res, err := New(fm, WithPlan()).Generate(ctx)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(res.Files), 4)
+		assert.Equal(t, res.Count(OutcomeCreated), 4)
+
+		// Nothing was written.
+		_, err = os.Stat(filepath.Join(res.Dir, routes))
+		assert.Equal(t, os.IsNotExist(err), true)
+	})
+
+	t.Run("memory", func(t *testing.T) {
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys)).Generate(ctx)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, res.Count(OutcomeCreated), 4)
+		assert.Equal(t, len(fsys.Paths()), 4)
+
+		// Nothing was written to disk.
+		_, err = os.Stat(filepath.Join(res.Dir, routes))
+		assert.Equal(t, os.IsNotExist(err), true)
+	})
+
+	t.Run("generate", func(t *testing.T) {
+		var logs bytes.Buffer
+		res, err := New(
+			WithLogger(&logs),
+			WithWorkerCount(1),
+			fm,
+		).Generate(ctx)
+		assert.Equal(t, err, nil)
+		assert.NotEqual(t, logs.Len(), 0)
+
+		// Sorted by scope, package and path.
+		f := res.Files[0]
+		assert.Equal(t, f.Scope, "domain")
+		assert.Equal(t, f.Package, "user")
+		assert.Equal(t, f.Outcome, OutcomeCreated)
+		assert.Equal(t, f.Written, true)
+
+		f = res.Files[len(res.Files)-1]
+		assert.Equal(t, f.Package, UniquePackage)
+		assert.Equal(t, f.Path, filepath.Join(res.Dir, routes))
+
+		b, err := os.ReadFile(f.Path)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, strings.Contains(string(b), "// USER"), true)
+	})
+
+	t.Run("check", func(t *testing.T) {
+		res, err := New(fm, WithCheck()).Generate(ctx)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, len(res.Drifted()), 0)
+		assert.Equal(t, res.OrphansChecked, true) // -> The previous subtest recorded a run.
+	})
+
+	// -> Partials of the library are called with the same functions as the templates of a job.
+	t.Run("library partials", func(t *testing.T) {
+		tts := filepath.Join(".codegen", "templates")
+		writeFile(t, filepath.Join(tts, "partials", "banner.tmpl"), `{{define "banner"}}// GENERATED{{range .Packages}} {{shout .Name}}{{end}}{{end}}`)
+		writeFile(t, filepath.Join(tts, "routes.tmpl"), `{{template "banner" .}}`)
+
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys)).Generate(ctx)
+		assert.Equal(t, err, nil)
+
+		b, err := fsys.ReadFile(filepath.Join(res.Dir, routes))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, strings.HasPrefix(string(b), "// GENERATED"), true)
+		assert.Equal(t, strings.Contains(string(b), "USER"), true)
+	})
+
+	t.Run("missing function", func(t *testing.T) {
+		_, err := New().Generate(ctx)
+		assert.NotEqual(t, err, nil)
+	})
+
+	t.Run("render context", func(t *testing.T) {
+		writeFile(t, filepath.Join(".codegen", "templates", "routes.tmpl"),
+			`{{.Scope.Key}} {{.Scope.Domain}} {{.Job.Key}} {{.Output.Name}} {{.Output.Dir}} {{.Output.Path}} {{len .Packages}} {{.Package}}`)
+
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys)).Generate(ctx)
+		assert.Equal(t, err, nil)
+
+		b, err := fsys.ReadFile(filepath.Join(res.Dir, routes))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), "routes http routes routes.go internal/routes internal/routes/routes.go 1 <nil>")
+	})
+
+	t.Run("vars", func(t *testing.T) {
+		t.Setenv("CODEGEN_API_VERSION", "v2")
+		config := filepath.Join(".codegen", "config.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		defer writeFile(t, config, string(b)) // -> The environment variable is unset once the test completes.
+		s := strings.Replace(string(b), "file-name: routes.go", "file-name: \\{vars.api.asTitle\\}.go", 1)
+		writeFile(t, config, s+"\nvars:\n  api: ${CODEGEN_API_VERSION}\n  owner: acme\n")
+		writeFile(t, filepath.Join(".codegen", "templates", "routes.tmpl"), `{{.Vars.api}} {{.Vars.owner}}`)
+
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys), WithVars(map[string]string{"owner": "cli"})).Generate(ctx)
+		assert.Equal(t, err, nil)
+
+		b, err = fsys.ReadFile(filepath.Join(res.Dir, "internal/routes/V2.go"))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), "v2 cli")
+	})
+
+	t.Run("undefined variable", func(t *testing.T) {
+		config := filepath.Join(".codegen", "config.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		defer writeFile(t, config, string(b))
+		writeFile(t, config, strings.Replace(string(b), "file-name: routes.go", "file-name: \\{vars.missing\\}.go", 1))
+
+		_, err = New(fm, WithFS(vfs.NewMemory())).Generate(ctx)
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, strings.Contains(err.Error(),
+			"scope 'routes', job 'routes': invalid file-name '\\{vars.missing\\}.go': '\\{vars.missing\\}' (position 0)"), true)
+	})
+
+	// -> Jobs whose file name refers to a model are executed for each model of the package.
+	t.Run("model token", func(t *testing.T) {
+		config := filepath.Join(".codegen", "config.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		s := strings.Replace(string(b), "file-name: methods.go", "file-name: \\{model.plural.asSnake\\}_\\{job\\}.go", 1)
+		writeFile(t, config, s)
+		writeFile(t, filepath.Join(".codegen", "templates", "methods.tmpl"), `{{.Model.Name}} {{.Output.Name}}`)
+
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys)).Generate(ctx)
+		assert.Equal(t, err, nil)
+
+		b, err = fsys.ReadFile(filepath.Join(res.Dir, "internal/domain/user/users_methods.go"))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), "User users_methods.go")
+	})
+
+	// -> Outputs referring to the package designate its directory; files are not written to a directory per package.
+	t.Run("output tokens", func(t *testing.T) {
+		config := filepath.Join(".codegen", "config.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		defer writeFile(t, config, string(b))
+		s := strings.Replace(string(b), "output: internal/domain", "output: internal/\\{pkg.asSnake\\}/\\{vars.layer\\}", 1)
+		writeFile(t, config, s+"\nvars:\n  layer: handler\n")
+		writeFile(t, filepath.Join(".codegen", "templates", "model.tmpl"), `{{.Scope.Output}} {{.Output.Dir}}`)
+
+		fsys := vfs.NewMemory()
+		res, err := New(fm, WithFS(fsys)).Generate(ctx)
+		assert.Equal(t, err, nil)
+
+		b, err = fsys.ReadFile(filepath.Join(res.Dir, "internal/user/handler/model.go"))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), "internal/user/handler internal/user/handler")
+
+		// -> Variables may not lead outside of the project.
+		_, err = New(fm, WithFS(vfs.NewMemory()), WithVars(map[string]string{"layer": "../../.."})).Generate(ctx)
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, strings.Contains(err.Error(), "resolves to '..', which is outside of the project"), true)
+	})
+
+	t.Run("file name outside the project", func(t *testing.T) {
+		config := filepath.Join(".codegen", "config.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		defer writeFile(t, config, string(b))
+		writeFile(t, config, strings.Replace(string(b), "file-name: routes.go", "file-name: \\{vars.n\\}.go", 1))
+
+		fsys := vfs.NewMemory()
+		_, err = New(fm, WithFS(fsys), WithVars(map[string]string{"n": "../../../../outside"})).Generate(ctx)
+		assert.NotEqual(t, err, nil)
+		assert.Equal(t, strings.Contains(err.Error(), "resolves to '../../../../outside.go', which may not refer to a "+
+			"parent directory"), true)
+
+		// -> Nested directories within the project remain allowed.
+		res, err := New(fm, WithFS(fsys), WithVars(map[string]string{"n": "v1/routes"})).Generate(ctx)
+		assert.Equal(t, err, nil)
+		_, err = fsys.ReadFile(filepath.Join(res.Dir, "internal/routes/v1/routes.go"))
+		assert.Equal(t, err, nil)
+	})
+
+	// -> Jobs whose 'override-on' refers to a changed package are executed by a run filtered on that package.
+	t.Run("filtered run", func(t *testing.T) {
+		config, billing := filepath.Join(".codegen", "config.yaml"), filepath.Join(".codegen", "pkg", "billing.yaml")
+		b, err := os.ReadFile(config)
+		assert.Equal(t, err, nil)
+		defer func() {
+			writeFile(t, config, string(b))
+			_ = os.Remove(billing)
+		}()
+		writeFile(t, config, strings.Replace(string(b), "override-on:\n            \\*:\n              model: true",
+			"override-on:\n            billing:\n              model: true", 1))
+		writeFile(t, billing, "name: billing\nmodels:\n  - name: Invoice\n")
+
+		res, err := New(fm).Generate(ctx)
+		assert.Equal(t, err, nil)
+		model := filepath.Join(res.Dir, "internal/domain/user/model.go")
+		writeFile(t, model, "stale")
+
+		// -> The model job of 'user' depends on 'billing', which changes.
+		time.Sleep(10 * time.Millisecond)
+		writeFile(t, billing, "name: billing\nmodels:\n  - name: Invoice\n  - name: Receipt\n")
+		_, err = gen.Execute(gen.Config{
+			WorkerCount:     1,
+			LogOutput:       io.Discard,
+			TemplateFuncMap: template.FuncMap{"shout": strings.ToUpper},
+			Filter:          &gen.JobFilter{Pkgs: []string{"billing"}},
+		}, time.Now())
+		assert.Equal(t, err, nil)
+
+		got, err := os.ReadFile(model)
+		assert.Equal(t, err, nil)
+		assert.NotEqual(t, string(got), "stale")
+	})
+}
+
+// setupTestDir scaffolds a '.codegen' directory within a temporary working directory; its unique template calls the
+// 'shout' function.
+func setupTestDir(t *testing.T) func() {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err = os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = scaffold.Write(dir, "", scaffold.PresetGo, false); err != nil {
+		t.Fatal(err)
+	}
+
+	tt := filepath.Join(dir, ".codegen/templates/routes.tmpl")
+	b, err := os.ReadFile(tt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, tt, string(b)+`{{range .Packages}}// {{shout .Name}}{{end}}`)
+	return func() { _ = os.Chdir(wd) }
+}
+
+func writeFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,120 @@
+package codegen
+
+import (
+	"sort"
+	"time"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/pkg/gen"
+	"github.com/maxzaleski/codegen/pkg/gen/modules"
+)
+
+type (
+	// Result represents the outcome of a generation.
+	Result struct {
+		// Began represents the time at which the generation began.
+		Began time.Time
+		// Duration represents the time taken by the generation.
+		Duration time.Duration
+		// Dir represents the directory against which the outputs of the scopes are resolved.
+		Dir string
+		// Files represents the files produced (or evaluated) by the jobs, sorted by scope, package and path.
+		Files []File
+		// OrphansChecked indicates whether orphaned files were established (see `WithCheck`); they cannot be without a
+		// previous run recorded locally (e.g. in a fresh checkout).
+		OrphansChecked bool
+	}
+
+	// File represents the outcome of a job in regard to its output file.
+	File struct {
+		// Scope represents the key of the scope the job belongs to.
+		Scope string
+		// Package represents the name of the package the job was executed for; `UniquePackage` for unique jobs.
+		Package string
+		// Path represents the absolute path of the file.
+		Path    string
+		Outcome Outcome
+		// Written indicates whether the file was (or, in plan mode, would be) written to disk.
+		Written bool
+		// Diff represents the unified diff between the existing and rendered file (see `WithDiff`).
+		Diff string
+	}
+
+	// Outcome represents the outcome of a job in regard to its output file.
+	Outcome = modules.FileOutcome
+)
+
+const (
+	OutcomeCreated     = modules.FileOutcomeCreated
+	OutcomeOverwritten = modules.FileOutcomeOverwritten
+	OutcomeIgnored     = modules.FileOutcomeIgnored
+
+	// Check mode outcomes (see `WithCheck`).
+	OutcomeUpToDate = modules.FileOutcomeUpToDate
+	OutcomeMissing  = modules.FileOutcomeMissing
+	OutcomeModified = modules.FileOutcomeModified
+	OutcomeOrphaned = modules.FileOutcomeOrphaned
+)
+
+// UniquePackage is the package name of the files produced by unique jobs.
+const UniquePackage = core.UniquePkgAlias
+
+func newResult(res *gen.Result, d time.Duration) *Result {
+	r := &Result{
+		Began:          res.Began,
+		Duration:       d,
+		Files:          make([]File, 0),
+		OrphansChecked: res.Metrics.GetOrphanCheckMetrics(),
+	}
+	if md := res.Metadata; md != nil {
+		r.Dir = md.Cwd
+	}
+
+	for sk, v := range res.Metrics.GetJobsMetrics() {
+		for pk, mjs := range v.(map[string][]modules.MetricJob) {
+			for _, mj := range mjs {
+				r.Files = append(r.Files, File{
+					Scope:   sk,
+					Package: pk,
+					Path:    mj.FileAbsolutePath,
+					Outcome: mj.Outcome,
+					Written: mj.FileCreated,
+					Diff:    mj.Diff,
+				})
+			}
+		}
+	}
+	sort.Slice(r.Files, func(i, j int) bool {
+		a, b := r.Files[i], r.Files[j]
+		if a.Scope != b.Scope {
+			return a.Scope < b.Scope
+		}
+		if a.Package != b.Package {
+			return a.Package < b.Package
+		}
+		return a.Path < b.Path
+	})
+	return r
+}
+
+// Count returns the number of files with the given outcome.
+func (r *Result) Count(o Outcome) int {
+	n := 0
+	for _, f := range r.Files {
+		if f.Outcome == o {
+			n++
+		}
+	}
+	return n
+}
+
+// Drifted returns the files that are out of date (see `WithCheck`).
+func (r *Result) Drifted() []File {
+	fs := make([]File, 0)
+	for _, f := range r.Files {
+		if f.Outcome.IsDrifted() {
+			fs = append(fs, f)
+		}
+	}
+	return fs
+}
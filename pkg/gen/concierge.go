@@ -1,25 +1,29 @@
 package gen
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"github.com/maxzaleski/codegen/internal/core"
 	"github.com/maxzaleski/codegen/internal/db"
 	"github.com/maxzaleski/codegen/internal/lib"
 	"github.com/maxzaleski/codegen/internal/lib/datastructure"
+	"github.com/maxzaleski/codegen/internal/lib/moddedstring"
 	"github.com/maxzaleski/codegen/internal/lib/slice"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/maxzaleski/codegen/pkg/gen/modules"
+	"github.com/maxzaleski/codegen/pkg/vfs"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
 	"os"
+	"path/filepath"
 	"strings"
 )
 
 type (
 	// IConcierge is the interface that wraps the generation concierge.
 	IConcierge interface {
-		Start(c Config, spec *core.Spec, scopes []*core.DomainScope)
+		Start(c Config, spec *core.Spec, scopes []*core.DomainScope) error
 		Wait() error
 	}
 
@@ -30,6 +34,7 @@ type (
 
 		errg *errgroup.Group
 		ds   []*core.DomainScope
+		fs   vfs.FS
 
 		// Modules.
 		queue       datastructure.IQueue[genJob]
@@ -43,7 +48,10 @@ type (
 func newConcierge(
 	errg *errgroup.Group, ctx IContext, c Config, logger slog.ILogger, db db.IDatabase, ds []*core.DomainScope,
 ) IConcierge {
-	metrics := ctx.GetMetrics()
+	metrics, cache := ctx.GetMetrics(), c.TemplateCache
+	if cache == nil {
+		cache = modules.NewTemplateCache()
+	}
 	s := &concierge{
 		ctx:    ctx,
 		errg:   errg,
@@ -51,10 +59,11 @@ func newConcierge(
 
 		metrics:     metrics,
 		ds:          ds,
+		fs:          c.fs(),
 		queue:       newQueue(logger, c),
 		logger:      newLogger(logger, "concierge", slog.Pink),
-		diagnostics: modules.NewDiagnostics(logger, db),
-		ttProcessor: modules.NewTemplateProcessor(ctx.GetPackages()),
+		diagnostics: modules.NewDiagnostics(logger, db, c.readOnly()),
+		ttProcessor: modules.NewTemplateProcessor(ctx.GetConfig().Types, ctx.GetMetadata(), c.fs(), cache, metrics),
 	}
 	return s
 }
@@ -68,7 +77,21 @@ func (rc *concierge) Wait() (err error) {
 		}
 	}(err)
 
-	if err = rc.errg.Wait(); err != nil {
+	if err = rc.errg.Wait(); err == nil {
+		hits, misses := rc.metrics.GetTemplateCacheMetrics()
+		logger.Log("templates", "msg", "template cache", "hits", hits, "misses", misses)
+
+		if rc.config.Check {
+			// -> Check mode: files produced by the last completed run must still be produced.
+			err = rc.captureOrphans()
+		} else {
+			// -> Only a successful run may advance the diagnostics baseline.
+			//
+			// A filtered run only produces a subset of the files; the remainder is carried over from the last run.
+			err = rc.diagnostics.Commit(rc.outputs(), rc.config.Filter != nil)
+		}
+	}
+	if err != nil {
 		logger.Log("main:error<-", "msg", "received an error", "err", err)
 
 		if rc.config.DebugVerbose {
@@ -80,7 +103,45 @@ func (rc *concierge) Wait() (err error) {
 	return
 }
 
-func (rc *concierge) Start(c Config, spec *core.Spec, scopes []*core.DomainScope) {
+// outputs returns the files produced by the current run.
+func (rc *concierge) outputs() []modules.DiagnosticsOutput {
+	os := make([]modules.DiagnosticsOutput, 0)
+	for sk, v := range rc.metrics.GetJobsMetrics() {
+		for pk, mjs := range v.(map[string][]modules.MetricJob) {
+			for _, mj := range mjs {
+				os = append(os, modules.DiagnosticsOutput{Scope: sk, Package: pk, Path: mj.FileAbsolutePath})
+			}
+		}
+	}
+	return os
+}
+
+// captureOrphans captures the files which are no longer produced by any job, yet remain on disk.
+//
+// Orphans are established against the outputs of the last completed run, as recorded by the local diagnostics
+// database; without one (e.g. a fresh checkout), none are captured, and the check is reported as such.
+func (rc *concierge) captureOrphans() error {
+	orphans, ok, err := rc.diagnostics.FindOrphans(rc.outputs())
+	if err != nil {
+		return errors.Wrap(err, "failed to find orphaned files")
+	}
+	rc.metrics.CaptureOrphanCheck(modules.MetricOrphanCheck{Checked: ok})
+	for _, o := range orphans {
+		if _, err = rc.fs.Stat(o.Path); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return errors.WithMessagef(err, "failed presence check at '%s'", o.Path)
+		}
+		rc.metrics.CaptureJob(o.Scope, o.Package, modules.MetricJob{
+			FileAbsolutePath: o.Path,
+			Outcome:          modules.FileOutcomeOrphaned,
+		})
+	}
+	return nil
+}
+
+func (rc *concierge) Start(c Config, spec *core.Spec, scopes []*core.DomainScope) error {
 	{
 		log := func(fields ...any) { rc.logger.Log("preflight", fields...) }
 		log("msg", "executing preflight functions")
@@ -88,8 +149,15 @@ func (rc *concierge) Start(c Config, spec *core.Spec, scopes []*core.DomainScope
 	}
 
 	// [1] Prepare diagnostics module.
-	if err := rc.diagnostics.Prepare(spec); err != nil {
-		panic(errors.Wrap(err, "concierge: failed to prepare diagnostics module"))
+	args, err := c.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "concierge: failed to marshal configuration")
+	}
+	if err = rc.diagnostics.Prepare(spec, args); err != nil {
+		return errors.Wrap(err, "concierge: failed to prepare diagnostics module")
+	}
+	if f := c.Filter; f != nil {
+		rc.diagnostics.Restrict(f.Pkgs) // -> Jobs depending on other packages may not be executed.
 	}
 
 	// [2] Extract jobs and feed the queue.
@@ -98,6 +166,8 @@ func (rc *concierge) Start(c Config, spec *core.Spec, scopes []*core.DomainScope
 
 	// [3] Start workers.
 	rc.errg.Go(func() error { return rc.startWorkers() })
+
+	return nil
 }
 
 func (rc *concierge) feedQueue(c Config, sm core.Metadata, scopes []*core.DomainScope, pkgs []*core.Package) error {
@@ -112,19 +182,23 @@ func (rc *concierge) feedQueue(c Config, sm core.Metadata, scopes []*core.Domain
 	// For each scope, we extract the jobs and enqueue them:
 	// • (1) If domain = 'http' && j.Unique, we only enqueue the job once
 	// • (2) Otherwise, we enqueue a copy of the job for each package (default)
-	fJs := make([]*genJob, 0)
+	// • (3) If the job's file name or its scope's output refers to a model, we enqueue a copy of the job for each model
+	//   of each package
+	fJs, cfg := make([]*genJob, 0), rc.ctx.GetConfig()
 	for _, scope := range rc.ds {
 		newJob := func(sj *core.ScopeJob) *genJob {
 			return &genJob{
 				Metadata: metadata{
-					Inline:     scope.Inline,
-					DomainType: scope.ParentType,
-					Metadata:   sm,
-					ScopeKey:   scope.Key,
-				},
-				OutputFile: &genJobFile{
-					AbsoluteDirPath: sm.Cwd + "/" + scope.Output,
+					Inline:      scope.Inline,
+					DomainType:  scope.ParentType,
+					Metadata:    sm,
+					ScopeKey:    scope.Key,
+					ScopeOutput: scope.Output,
+					JobKey:      sj.Key,
+					// -> Variables of the command line take precedence over those of the configuration.
+					Vars: core.MergeVars(cfg.Vars, scope.Vars, sj.Vars, c.Vars),
 				},
+				OutputFile:       &genJobFile{},
 				DisableTemplates: c.IgnoreTemplates,
 				ScopeJob:         sj,
 			}
@@ -141,18 +215,44 @@ func (rc *concierge) feedQueue(c Config, sm core.Metadata, scopes []*core.Domain
 			js := slice.Map(pkgs,
 				func(p *core.Package) *genJob {
 					jPkg := newJob(sJob.Copy())
-					jPkg.ScopeJob.Key = fmt.Sprintf("%s-%s", p.Name, jPkg.ScopeJob.Key)
+					jPkg.ScopeJob.Key = fmt.Sprintf("%s-%s", p.Path, jPkg.ScopeJob.Key)
 					jPkg.Package = p
 					return jPkg
 				})
+			// -> Scenario (3): jobs whose file name or output refers to a model are executed for each model of the package.
+			if moddedstring.References(sJob.FileName, moddedstring.TokenModel) ||
+				moddedstring.References(scope.Output, moddedstring.TokenModel) {
+				js = perModel(js)
+			}
 			fJs = append(fJs, js...)
 		}
 	}
 
+	// -> Retain the jobs affected by the filter, if any.
+	if f := c.Filter; f != nil {
+		fJs = slice.Filter(fJs, f.matches)
+	}
+
 	// [2] Feed the queue.
 	return rc.enqueue(fJs)
 }
 
+// perModel returns a copy of the given package jobs for each model of their package.
+func perModel(js []*genJob) []*genJob {
+	mJs := make([]*genJob, 0)
+	for _, j := range js {
+		for i := range j.Package.Models {
+			jm := *j
+			jm.ScopeJob = j.ScopeJob.Copy()
+			jm.ScopeJob.Key = fmt.Sprintf("%s-%s", j.ScopeJob.Key, j.Package.Models[i].Name)
+			jm.OutputFile = &genJobFile{}
+			jm.Model = &j.Package.Models[i]
+			mJs = append(mJs, &jm)
+		}
+	}
+	return mJs
+}
+
 func (rc *concierge) enqueue(js []*genJob) error {
 	defer func() {
 		rc.queue.Ready()
@@ -239,7 +339,7 @@ func (rc *concierge) worker(id int) error {
 	defer logger.Log("exit", "msg", "worker exiting")
 
 	exec := func(j *genJob) (err error) {
-		logOutcome := func(o jobOutcome) {
+		logOutcome := func(o modules.FileOutcome) {
 			fn := strings.Replace(j.OutputFile.AbsolutePath, j.Metadata.Cwd, "", 1)
 			logger.Ack("file", j, "status", string(o), "file", fn)
 		}
@@ -256,55 +356,84 @@ func (rc *concierge) worker(id int) error {
 			&modules.MetricJob{FileAbsolutePath: j.OutputFile.AbsolutePath},
 			core.UniquePkgAlias
 		if p := j.Package; p != nil {
-			pk = p.Name
+			pk = p.Path
 		}
 		defer func() { metrics.CaptureJob(sk, pk, *mj) }() // deferred as to allow mutation.
 
+		// -> Establish whether the output file already exists.
+		exists := false
+		if _, err = rc.fs.Stat(j.OutputFile.AbsolutePath); err != nil {
+			if !os.IsNotExist(err) {
+				return errors.WithMessagef(err, "failed presence check at '%s'", j.OutputFile.AbsolutePath)
+			}
+			err = nil
+		} else {
+			exists = true
+		}
+
+		// -> Clean mode: remove the existing file; nothing is rendered.
+		if rc.config.Clean {
+			if mj.Outcome, err = rc.clean(j, exists); err == nil {
+				defer logOutcome(mj.Outcome)
+			}
+			return
+		}
+
+		// -> Check mode: compare the rendered file against the existing one if regenerated on every run; files created
+		// once need only exist. Nothing is written to disk.
+		if rc.config.Check {
+			if mj.Outcome, mj.Diff, err = rc.check(j, exists); err == nil {
+				defer logOutcome(mj.Outcome)
+			}
+			return
+		}
+
 		// [2] Evaluate whether to proceed.
 		//
 		// • Override: true, always run job
 		// • OverrideOn: should run iff the '.codegen/pkg' contents have changed per the `OverrideOn`'s specificities.
 		if !j.Override {
-			// [EXPERIMENTAL]: overrideOn: uncompleted.
-			//if len(j.OverrideOn) != 0 {
-			//	logger.Log("eval", "msg", "verifying configuration files")
-			//
-			//	// -> Check whether the configuration files have changed.
-			//	var ok bool
-			//	ok, err = rc.diagnostics.Verify(j.OverrideOn)
-			//	if err != nil {
-			//		return errors.Wrap(err, "failed to verify configuration files")
-			//	}
-			//
-			//	// -> ok: no change was detected since last use; exit.
-			//	if ok {
-			//		defer logOutcome(fileOutcomeIgnored)
-			//		return
-			//	}
-			//}
-
-			if _, err = os.Stat(j.OutputFile.AbsolutePath); err != nil {
-				if !os.IsNotExist(err) {
-					return errors.WithMessagef(err, "failed presence check at '%s'", j.OutputFile.AbsolutePath)
+			changed := false
+			if len(j.OverrideOn) != 0 {
+				logger.Log("eval", "msg", "verifying configuration files")
+
+				// -> Check whether the configuration files have changed since the last completed run.
+				if changed, err = rc.diagnostics.Verify(j.Package, j.OverrideOn); err != nil {
+					return errors.Wrap(err, "failed to verify configuration files")
 				}
-			} else {
-				defer logOutcome(fileOutcomeIgnored)
+			}
+
+			// -> !changed: no change was detected since last use; only proceed if the file is absent.
+			if !changed && exists {
+				mj.Outcome = modules.FileOutcomeIgnored
+				defer logOutcome(mj.Outcome)
 				return
 			}
 		}
+		outcome := modules.FileOutcomeCreated
+		if exists {
+			outcome = modules.FileOutcomeOverwritten
+		}
 
 		// [3] Execute templates.
-		if err = rc.ttProcessor.Exec(
-			j.Templates,
-			j.DisableTemplates,
-			j.Package,
-			j.OutputFile.AbsolutePath,
-			j.OutputFile.Ext,
-			rc.config.TemplateFuncMap,
-		); err == nil {
-			mj.FileCreated = true
-			defer logOutcome(fileOutcomeSuccess)
+		b, err := rc.render(j)
+		if err != nil {
+			return
+		}
+		// -> Diff mode: compare the rendered file against the existing one.
+		if rc.config.Diff && exists {
+			if mj.Diff, err = diffFile(rc.fs, j.Metadata.Cwd, j.OutputFile.AbsolutePath, b); err != nil {
+				return
+			}
+		}
+		// -> Plan mode: nothing is written to disk.
+		if !rc.config.Plan {
+			if err = rc.ttProcessor.Write(b, j.OutputFile.AbsolutePath); err != nil {
+				return
+			}
 		}
+		mj.FileCreated, mj.Outcome = true, outcome
+		defer logOutcome(outcome)
 
 		return
 	}
@@ -328,3 +457,104 @@ func (rc *concierge) worker(id int) error {
 		}
 	}
 }
+
+func (rc *concierge) render(j *genJob) ([]byte, error) {
+	return rc.ttProcessor.Render(
+		j.Templates,
+		j.DisableTemplates,
+		rc.renderContext(j),
+		j.OutputFile.Ext,
+		rc.config.TemplateFuncMap,
+	)
+}
+
+// renderContext returns the data the templates of the given job are executed with.
+func (rc *concierge) renderContext(j *genJob) *modules.RenderContext {
+	md, f := j.Metadata, j.OutputFile
+	rel := func(path string) string {
+		if r, err := filepath.Rel(md.Cwd, path); err == nil {
+			return filepath.ToSlash(r)
+		}
+		return path
+	}
+	return &modules.RenderContext{
+		Package:  j.Package,
+		Model:    j.Model,
+		Packages: rc.ctx.GetPackages(),
+		Scope: modules.RenderScope{
+			Key:    md.ScopeKey,
+			Domain: md.DomainType.Key(),
+			Output: md.ScopeOutput,
+			Inline: md.Inline,
+		},
+		Job: modules.RenderJob{
+			Key:      md.JobKey,
+			Unique:   j.Unique,
+			Override: j.Override,
+		},
+		Output: modules.RenderOutput{
+			Name: f.Name,
+			Ext:  f.Ext,
+			Dir:  rel(f.AbsoluteDirPath),
+			Path: rel(f.AbsolutePath),
+		},
+		Config: rc.ctx.GetConfig(),
+		Vars:   md.Vars,
+	}
+}
+
+// check determines whether the job's output file has drifted from its rendered counterpart.
+//
+// Only files owned by the generator (i.e. `override` or `override-on`) are compared byte for byte; other files are
+// meant to be edited once created, and are only expected to exist. This is deliberate: comparing them would report
+// every legitimate edit as drift.
+func (rc *concierge) check(j *genJob, exists bool) (o modules.FileOutcome, d string, err error) {
+	// Templates are always rendered as to surface errors.
+	b, err := rc.render(j)
+	if err != nil {
+		return
+	}
+	if !exists {
+		return modules.FileOutcomeMissing, "", nil
+	}
+	if !j.Override && len(j.OverrideOn) == 0 {
+		return modules.FileOutcomeUpToDate, "", nil
+	}
+
+	path := j.OutputFile.AbsolutePath
+	old, err := rc.fs.ReadFile(path)
+	if err != nil {
+		return "", "", errors.Wrapf(err, "failed to read file at '%s'", path)
+	}
+	if bytes.Equal(old, b) {
+		return modules.FileOutcomeUpToDate, "", nil
+	}
+	if rc.config.Diff {
+		d = unifiedDiff(j.Metadata.Cwd, path, old, b)
+	}
+	return modules.FileOutcomeModified, d, nil
+}
+
+// clean removes the job's output file if owned by the generator, alongside its directory if left empty.
+//
+// Files meant to be edited once created are only removed if `CleanAll` is set. In plan mode, nothing is removed.
+func (rc *concierge) clean(j *genJob, exists bool) (modules.FileOutcome, error) {
+	if !exists {
+		return modules.FileOutcomeMissing, nil
+	}
+	if !j.Override && len(j.OverrideOn) == 0 && !rc.config.CleanAll {
+		return modules.FileOutcomeIgnored, nil
+	}
+	if rc.config.Plan {
+		return modules.FileOutcomeRemoved, nil
+	}
+
+	path := j.OutputFile.AbsolutePath
+	if err := rc.fs.Remove(path); err != nil {
+		return "", errors.Wrapf(err, "failed to remove file at '%s'", path)
+	}
+	// Fails if the directory is not empty; this is expected.
+	_ = rc.fs.Remove(filepath.Dir(path))
+
+	return modules.FileOutcomeRemoved, nil
+}
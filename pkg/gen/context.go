@@ -16,6 +16,8 @@ type (
 		GetLogger() slog.ILogger
 		GetMetrics() modules.IMetrics
 		GetPackages() []*core.Package
+		GetConfig() *core.Config
+		GetMetadata() *core.Metadata
 		SetUnderlying(ctx context.Context)
 		SetAny(key internal.ContextKey, val any)
 	}
@@ -30,6 +32,8 @@ const (
 	contextKeyLogger   internal.ContextKey = "logger"
 	contextKeyMetrics  internal.ContextKey = "metrics.go"
 	contextKeyPackages internal.ContextKey = "packages"
+	contextKeyConfig   internal.ContextKey = "config"
+	contextKeyMetadata internal.ContextKey = "metadata"
 )
 
 var _ IContext = (*genContext)(nil)
@@ -58,6 +62,14 @@ func (c *genContext) GetPackages() []*core.Package {
 	return c.ctx.Value(contextKeyPackages).([]*core.Package)
 }
 
+func (c *genContext) GetConfig() *core.Config {
+	return c.ctx.Value(contextKeyConfig).(*core.Config)
+}
+
+func (c *genContext) GetMetadata() *core.Metadata {
+	return c.ctx.Value(contextKeyMetadata).(*core.Metadata)
+}
+
 func (c *genContext) SetUnderlying(ctx context.Context) {
 	c.ctx = ctx
 }
@@ -7,8 +7,10 @@ import (
 	"github.com/maxzaleski/codegen/internal/db"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/maxzaleski/codegen/pkg/gen/modules"
+	"github.com/maxzaleski/codegen/pkg/vfs"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
+	"io"
 	"text/template"
 	"time"
 
@@ -31,13 +33,47 @@ type (
 		DisableLogFile bool `json:"disable_log_file"`
 		// Location of the tool's folder; default: '{cwd}/.codegen'.
 		Location string `json:"location"`
+		// Check mode; compare the files regenerated on every run ('override', 'override-on') against their rendered
+		// content, and verify that files created once exist, without writing to disk.
+		Check bool `json:"check"`
+		// Diff mode; compute the unified diff of each overwritten file.
+		Diff bool `json:"diff"`
+		// Plan mode; report what each job would do without writing to disk.
+		Plan bool `json:"plan"`
+		// Clean mode; remove the files owned by the generator (i.e. `override` or `override-on`).
+		Clean bool `json:"clean"`
+		// Clean mode only; also remove the files meant to be edited once created.
+		CleanAll bool `json:"clean_all"`
 		// Number of workers available in the runtime concierge.
 		WorkerCount int `json:"worker_count"`
+		// Vars represents user-defined variables; they take precedence over those declared by the configuration (see
+		// `core.Config.Vars`).
+		Vars map[string]string `json:"vars,omitempty"`
+		// Filter restricts the jobs to be executed; all jobs are executed if nil.
+		Filter *JobFilter `json:"filter,omitempty"`
 		// TemplateFuncMap is a map of functions that can be called from templates.
-		TemplateFuncMap template.FuncMap
+		TemplateFuncMap template.FuncMap `json:"-"`
+		// LogOutput is the destination of debug logs; the standard logger is used if nil.
+		LogOutput io.Writer `json:"-"`
+		// FS is the filesystem generated files are written to; the real disk is used if nil.
+		FS vfs.FS `json:"-"`
+		// TemplateCache holds the compiled template sets; a new cache is used by each execution if nil. Sharing a cache
+		// across executions (e.g. watch mode) avoids recompiling the templates that did not change; template sets are
+		// keyed by the identity of `TemplateFuncMap`, which must then not be modified.
+		TemplateCache modules.ITemplateCache `json:"-"`
+	}
+
+	// JobFilter represents the jobs affected by a change; a job is executed if it matches any of the criteria.
+	JobFilter struct {
+		// Packages whose jobs are to be executed, as referenced by `core.Package.Path`; unique jobs, and jobs whose
+		// `override-on` refers to a listed package, are executed too.
+		Pkgs []string `json:"pkgs"`
+		// Templates whose jobs are to be executed, as referenced by `core.ScopeJobTemplate.Name`.
+		Templates []string `json:"templates"`
 	}
 
 	Result struct {
+		Began    time.Time
 		Metadata *core.Metadata
 		Metrics  modules.IMetrics
 	}
@@ -47,12 +83,43 @@ func (c Config) Marshal() ([]byte, error) {
 	return json.Marshal(c)
 }
 
-func Execute(c Config, began time.Time) (res *Result, err error) {
-	logger := slog.New(c.DebugMode, began)
+// fs returns the filesystem generated files are written to.
+func (c Config) fs() vfs.FS {
+	if c.FS != nil {
+		return c.FS
+	}
+	return vfs.OS()
+}
+
+// readOnly returns true if the configuration prevents writing generated files to disk; the diagnostics baseline is
+// left untouched, as it describes the files on disk.
+func (c Config) readOnly() bool {
+	return c.Plan || c.Check || c.Clean || !vfs.IsOS(c.fs())
+}
+
+// logger returns the root logger as per the configuration.
+func (c Config) logger(began time.Time) slog.ILogger {
+	if c.LogOutput != nil {
+		return slog.NewWithOutput(c.LogOutput, c.DebugMode, began)
+	}
+	return slog.New(c.DebugMode, began)
+}
+
+// Execute executes the code generation; see `ExecuteContext`.
+func Execute(c Config, began time.Time) (*Result, error) {
+	return ExecuteContext(context.Background(), c, began)
+}
+
+// ExecuteContext executes the code generation until completion, or until the context is cancelled.
+//
+// The result is always returned; its metrics reflect the jobs executed prior to an error, if any.
+func ExecuteContext(pctx context.Context, c Config, began time.Time) (res *Result, err error) {
+	logger := c.logger(began)
 
 	// [1] Parse configuration via `.codegen` directory.
 	spec, err1 := core.NewSpec(logger, c.Location)
 	res = &Result{
+		Began:    began,
 		Metadata: spec.Metadata, // Always returned; error handled second.
 		Metrics:  modules.NewMetrics(),
 	}
@@ -62,37 +129,38 @@ func Execute(c Config, began time.Time) (res *Result, err error) {
 	}
 
 	// -> [dev] Act upon the flag; delete tmp directory.
-	if c.DeleteTmp {
+	if c.DeleteTmp && !c.readOnly() {
 		if err = removeTmpDir(res.Metadata, logger); err != nil {
 			return
 		}
 	}
 
-	errg, ctx := errgroup.WithContext(context.Background())
+	errg, ctx := errgroup.WithContext(pctx)
 	gctx := newGenContext(ctx)
 	gctx.SetAny(contextKeyBegan, began)
 	gctx.SetAny(contextKeyLogger, logger)
 	gctx.SetAny(contextKeyMetrics, res.Metrics)
 	gctx.SetAny(contextKeyPackages, spec.Pkgs)
+	gctx.SetAny(contextKeyConfig, spec.Config)
+	gctx.SetAny(contextKeyMetadata, spec.Metadata)
 
 	// [2] Start local sqlite database.
-	dbc, err2 := db.New(logger, spec.Metadata.CodegenDir)
+	dbc, err2 := db.New(logger, spec.Metadata.CodegenDir, c.readOnly())
 	if err = err2; err != nil {
 		return
 	}
 	defer func(conn *sql.DB) { _ = conn.Close() }(dbc.Conn())
 
 	// [3] Aggregate scopes from both domains.
-	hScopes, pScopes := spec.Config.HttpDomain.Scopes, spec.Config.PkgDomain.Scopes
-	ds := make([]*core.DomainScope, 0, len(hScopes)+len(pScopes))
-	ds = append(ds, hScopes...)
-	ds = append(ds, pScopes...)
+	ds := spec.Config.Scopes()
 
 	// [4] Start the runtime concierge.
 	rc := newConcierge(errg, gctx, c, logger, dbc, ds)
 
 	// -> Begin generation.
-	rc.Start(c, spec, ds)
+	if err = rc.Start(c, spec, ds); err != nil {
+		return
+	}
 
 	// -> [blocking] wait for all goroutines to terminate.
 	if err = rc.Wait(); err == nil {
@@ -2,8 +2,10 @@ package gen
 
 import (
 	"github.com/maxzaleski/codegen/internal/core"
-	"github.com/maxzaleski/codegen/internal/fs"
 	"github.com/maxzaleski/codegen/internal/lib/moddedstring"
+	"github.com/maxzaleski/codegen/internal/lib/slice"
+	"github.com/pkg/errors"
+	"path"
 	"strings"
 )
 
@@ -11,9 +13,12 @@ type (
 	genJob struct {
 		*core.ScopeJob
 
-		OutputFile       *genJobFile
-		Metadata         metadata
-		Package          *core.Package
+		OutputFile *genJobFile
+		Metadata   metadata
+		Package    *core.Package
+		// Model represents the model the job is executed for, if its file name refers to one (see
+		// `moddedstring.TokenModel`).
+		Model            *core.Model
 		DisableTemplates bool
 	}
 
@@ -27,63 +32,117 @@ type (
 	metadata struct {
 		core.Metadata
 
-		ScopeKey   string
-		DomainType core.DomainType
-		Inline     bool
+		ScopeKey string
+		// ScopeOutput represents the output directory of the scope, relative to the working directory; its tokens are
+		// expanded upon preparing the job (e.g. 'internal/\{pkg\}' => 'internal/user').
+		ScopeOutput string
+		DomainType  core.DomainType
+		Inline      bool
+		// JobKey represents the key of the job, as declared by the configuration; `ScopeJob.Key` is prefixed by the
+		// path of the package the job is executed for.
+		JobKey string
+		// Vars represents the variables of the job, merged across levels (see `core.MergeVars`).
+		Vars core.Vars
 	}
 )
 
-const tokenPkg = "pkg"
-
-// Prepare prepares the job for execution by filling-in missing fields, and verifying output directory structure.
-func (j *genJob) Prepare() (err error) {
-	if err = j.fill(); err != nil {
-		return
-	}
-	if err = j.checkOutputDirPresence(map[string]any{}); err != nil {
-		return
-	}
-	return
+// Prepare prepares the job for execution by filling-in missing fields.
+//
+// It has no side effects; the output directory structure is created upon writing the file.
+func (j *genJob) Prepare() error {
+	return j.fill()
 }
 
 func (j *genJob) fill() (err error) {
-	// [1] Set output file name and extension.
-	cs, f := strings.Split(j.FileName, "."), j.OutputFile
-	f.Ext = cs[len(cs)-1]
-
-	tm := map[string]string{}
+	md := &j.Metadata
+	tm := map[string]string{
+		moddedstring.TokenScope:  md.ScopeKey,
+		moddedstring.TokenJob:    md.JobKey,
+		moddedstring.TokenDomain: md.DomainType.Key(),
+	}
 	if j.Package != nil {
-		tm[tokenPkg] = j.Package.Name
+		tm[moddedstring.TokenPkg] = j.Package.Name
+		tm[moddedstring.TokenPkgPath] = j.Package.Path
+	}
+	if j.Model != nil {
+		tm[moddedstring.TokenModel] = j.Model.Name
+	}
+	for k, v := range md.Vars {
+		tm[moddedstring.TokenVars+"."+k] = v
+	}
+
+	// [1] Set output directory; it may refer to the same tokens as the file name (e.g. 'internal/\{pkg\}/handler').
+	f, outputRefersToPkg := j.OutputFile, moddedstring.References(md.ScopeOutput, moddedstring.TokenPkg)
+	out, err := moddedstring.New(md.ScopeOutput, tm)
+	if err != nil {
+		return errors.Wrapf(err, "scope '%s', job '%s': invalid output '%s'", md.ScopeKey, md.JobKey, md.ScopeOutput)
+	}
+	// -> Values may not be trusted to designate a directory within the project (e.g. '\{vars.dir\}' = '../app').
+	if out = path.Clean(strings.TrimPrefix(out, "/")); out == ".." || strings.HasPrefix(out, "../") {
+		return errors.Errorf("scope '%s', job '%s': output '%s' resolves to '%s', which is outside of the project",
+			md.ScopeKey, md.JobKey, md.ScopeOutput, out)
 	}
+	md.ScopeOutput, f.AbsoluteDirPath = out, md.Cwd+"/"+out
+
+	// [2] Set output file name and extension.
+	cs := strings.Split(j.FileName, ".")
+	f.Ext = cs[len(cs)-1]
 	if f.Name, err = moddedstring.New(j.FileName, tm); err != nil {
-		return
+		return errors.Wrapf(err, "scope '%s', job '%s': invalid file-name '%s'", md.ScopeKey, md.JobKey, j.FileName)
+	} else if f.Name == "" {
+		// -> Otherwise, the output path would designate the output directory itself.
+		return errors.Errorf("scope '%s', job '%s': file-name '%s' resolves to an empty name",
+			md.ScopeKey, md.JobKey, j.FileName)
+	} else if slice.Contains(strings.Split(f.Name, "/"), "..", nil) {
+		// -> As for the output, values may not be trusted (e.g. '\{vars.name\}' = '../../app').
+		return errors.Errorf("scope '%s', job '%s': file-name '%s' resolves to '%s', which may not refer to a parent "+
+			"directory", md.ScopeKey, md.JobKey, j.FileName, f.Name)
 	}
 
-	// [2] Set output file absolute path.
-	fn, md, pkg := f.Name, j.Metadata, j.Package
+	// [3] Set output file absolute path.
+	fn, pkg := f.Name, j.Package
 	f.AbsolutePath = f.AbsoluteDirPath + "/"
 
 	// (i) Inline: files are generated within the same directory space (e.g. models > User.Java, Car.Java).
 	// (i) Unique: job is only to be performed once for the specified output.
-	if md.Inline || j.Unique {
+	// (i) Output refers to the package: the output directory is that of the package already (e.g. 'internal/\{pkg\}').
+	// (i) Otherwise: files are generated within the directory of their package, as per its path (e.g. billing/invoice).
+	if md.Inline || j.Unique || outputRefersToPkg {
 		f.AbsolutePath += fn
 	} else if pkg != nil {
-		f.AbsoluteDirPath += "/" + pkg.Name
-		f.AbsolutePath += pkg.Name + "/" + fn
+		f.AbsoluteDirPath += "/" + pkg.Path
+		f.AbsolutePath += pkg.Path + "/" + fn
 	}
 
+	// -> Whatever the tokens resolve to, the file must remain within the project.
+	if f.AbsolutePath = path.Clean(f.AbsolutePath); !strings.HasPrefix(f.AbsolutePath, path.Clean(md.Cwd)+"/") {
+		return errors.Errorf("scope '%s', job '%s': file '%s' is outside of the project", md.ScopeKey, md.JobKey,
+			f.AbsolutePath)
+	}
 	return
 }
 
-func (j *genJob) checkOutputDirPresence(seenMap map[string]any) error {
-	if j.Unique || j.Metadata.Inline {
-		key := j.Metadata.ScopeKey + "/" + j.OutputFile.Name
-		if _, ok := seenMap[key]; ok {
-			return nil
+// matches returns true if the job is affected by the filter.
+//
+// Jobs whose `override-on` refers to a listed package are affected too, as they depend on it; the baseline of the
+// package is advanced by the run (see `IDiagnostics.Restrict`).
+func (f *JobFilter) matches(j *genJob) bool {
+	if j.Package == nil {
+		if len(f.Pkgs) != 0 {
+			return true
 		}
-		seenMap[key] = nil
+	} else if slice.Contains(f.Pkgs, j.Package.Path, nil) {
+		return true
 	}
-
-	_, err := fs.CreateDirINE(j.OutputFile.AbsoluteDirPath)
-	return err
+	for key := range j.OverrideOn {
+		if slice.Contains(f.Pkgs, key, nil) {
+			return true
+		}
+	}
+	for _, t := range j.Templates {
+		if slice.Contains(f.Templates, t.Name, nil) {
+			return true
+		}
+	}
+	return false
 }
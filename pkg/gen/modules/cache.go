@@ -0,0 +1,130 @@
+package modules
+
+import (
+	"crypto/sha256"
+	"os"
+	"sync"
+	"text/template"
+)
+
+type (
+	ITemplateCache interface {
+		// Get returns the template set of the given key, along with the files it was compiled from; the set is compiled
+		// via `compile` upon a miss, or whenever one of its files changed since. Returns true upon a hit.
+		//
+		// The returned set is shared by all callers; it may be executed concurrently, but must not be modified (see
+		// `template.Template.Clone`).
+		Get(key string, compile TemplateCompiler) (*template.Template, []string, bool, error)
+	}
+
+	// TemplateCompiler compiles a template set; it returns the files (or directories) the set was read from, as for
+	// the set to be invalidated upon their change.
+	TemplateCompiler func() (*template.Template, []string, error)
+
+	templateCache struct {
+		mu      sync.Mutex
+		entries map[string]*templateCacheEntry
+	}
+
+	templateCacheEntry struct {
+		// Serialises the compilation of the entry; concurrent callers wait for the first one to complete.
+		mu     sync.Mutex
+		tt     *template.Template
+		files  []string
+		stamps map[string]fileStamp
+		// Represents the compiler of the set; it is retained as for the values it captures to outlive the entry, in
+		// case its key refers to their address (e.g. a FuncMap).
+		compile TemplateCompiler
+	}
+
+	// fileStamp represents the state of a file at the time its template set was compiled.
+	fileStamp struct {
+		modTime int64
+		size    int64
+		dir     bool
+		// Represents the SHA-256 checksum of the file; zero for directories.
+		sum [sha256.Size]byte
+	}
+)
+
+// NewTemplateCache returns a new instance of `ITemplateCache`; it is safe for concurrent use.
+func NewTemplateCache() ITemplateCache {
+	return &templateCache{entries: make(map[string]*templateCacheEntry)}
+}
+
+func (c *templateCache) Get(key string, compile TemplateCompiler) (*template.Template, []string, bool, error) {
+	c.mu.Lock()
+	e, ok := c.entries[key]
+	if !ok {
+		e = &templateCacheEntry{}
+		c.entries[key] = e
+	}
+	c.mu.Unlock()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.tt != nil && e.valid() {
+		return e.tt, e.files, true, nil
+	}
+
+	tt, files, err := compile()
+	if err != nil {
+		e.tt = nil // -> Errors are not cached; the next caller attempts the compilation again.
+		return nil, nil, false, err
+	}
+	e.tt, e.files, e.stamps, e.compile = tt, files, make(map[string]fileStamp, len(files)), compile
+	for _, f := range files {
+		if s, err := stamp(f, nil); err == nil {
+			e.stamps[f] = s
+		}
+	}
+	return tt, files, false, nil
+}
+
+// valid returns true if none of the files of the entry changed since its compilation.
+//
+// Files are first compared by modification time and size; a file that was touched is only considered changed if its
+// checksum differs, in which case its stamp is refreshed. Directories (e.g. the templates library) are compared by
+// modification time only, as to catch the addition or removal of files.
+func (e *templateCacheEntry) valid() bool {
+	for _, f := range e.files {
+		old, ok := e.stamps[f]
+		if !ok {
+			return false
+		}
+		s, err := stamp(f, &old)
+		if err != nil || s.dir != old.dir || (s.dir && s.modTime != old.modTime) || s.sum != old.sum {
+			return false
+		}
+		e.stamps[f] = s
+	}
+	return true
+}
+
+// stamp returns the stamp of the file at `path`; its checksum is only computed if it differs from `prev` by
+// modification time or size. A missing file has a zero stamp, as for its creation to be detected.
+func stamp(path string, prev *fileStamp) (fileStamp, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return fileStamp{}, nil
+		}
+		return fileStamp{}, err
+	}
+	s := fileStamp{modTime: fi.ModTime().UnixNano(), size: fi.Size(), dir: fi.IsDir()}
+	if s.dir {
+		return s, nil
+	}
+	if prev != nil && prev.modTime == s.modTime && prev.size == s.size {
+		s.sum = prev.sum
+		return s, nil
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return fileStamp{}, err
+	}
+	s.sum = sha256.Sum256(b)
+	return s, nil
+}
@@ -0,0 +1,76 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"text/template"
+	"time"
+
+	"github.com/go-playground/assert"
+)
+
+func TestTemplateCache_Get(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "model.tmpl")
+	write := func(content string, modTime time.Time) {
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	now := time.Now()
+	write("v1", now.Add(-time.Hour))
+
+	var compiled int32
+	compile := func() (*template.Template, []string, error) {
+		atomic.AddInt32(&compiled, 1)
+		tt, err := template.ParseFiles(path)
+		return tt, []string{dir, path}, err
+	}
+	c := NewTemplateCache()
+
+	t.Run("concurrent", func(t *testing.T) {
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, _, _, err := c.Get("key", compile)
+				assert.Equal(t, err, nil)
+			}()
+		}
+		wg.Wait()
+		assert.Equal(t, atomic.LoadInt32(&compiled), int32(1))
+	})
+
+	tests := []struct {
+		name   string
+		change func()
+		hit    bool
+	}{
+		{"unchanged", func() {}, true},
+		{"touched", func() { write("v1", now) }, true},
+		{"modified", func() { write("v2", now.Add(time.Minute)) }, false},
+		{"file added", func() {
+			if err := os.WriteFile(filepath.Join(dir, "partial.tmpl"), nil, 0644); err != nil {
+				t.Fatal(err)
+			}
+			_ = os.Chtimes(dir, now.Add(time.Hour), now.Add(time.Hour))
+		}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.change()
+			_, files, hit, err := c.Get("key", compile)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, hit, test.hit)
+			assert.Equal(t, files, []string{dir, path})
+		})
+	}
+}
@@ -5,5 +5,8 @@ import "github.com/maxzaleski/codegen/pkg/gen/modules/diagnostics"
 // IDiagnostics is an alias for diagnostics.IDiagnostics.
 type IDiagnostics = diagnostics.IDiagnostics
 
+// DiagnosticsOutput is an alias for diagnostics.Output.
+type DiagnosticsOutput = diagnostics.Output
+
 // NewDiagnostics is an alias for diagnostics.New.
 var NewDiagnostics = diagnostics.New
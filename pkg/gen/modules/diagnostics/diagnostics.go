@@ -4,25 +4,59 @@ import (
 	"context"
 	"github.com/maxzaleski/codegen/internal/core"
 	"github.com/maxzaleski/codegen/internal/db"
+	"github.com/maxzaleski/codegen/internal/lib/slice"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/mitchellh/hashstructure/v2"
-	"golang.org/x/sync/errgroup"
+	"github.com/pkg/errors"
+	"sort"
+	"strings"
+	"sync"
 )
 
 type (
 	IDiagnostics interface {
-		// Prepare prepares the diagnostics module for utilisation.
-		Prepare(spec *core.Spec) error
+		// Prepare prepares the diagnostics module for utilisation; a new run is recorded with the given arguments.
+		Prepare(spec *core.Spec, args []byte) error
+		// Restrict limits the snapshots recorded by the run to the given packages (e.g. a filtered run); snapshots of
+		// other packages are compared, but not recorded, as jobs depending on them may not be executed. All packages
+		// are recorded unless restricted.
+		Restrict(pkgs []string)
 		// Verify checks whether a package has changed based on the override rules.
-		Verify(map[string]core.ScopeJobOverride) (bool, error)
+		//
+		// `pkg` is the package the job is generated for; it is used to resolve `core.OverrideOnWildcard`.
+		Verify(pkg *core.Package, overrOn map[string]core.ScopeJobOverride) (bool, error)
+		// Commit marks the current run as completed; its snapshots become the baseline of subsequent runs.
+		//
+		// `outputs` are the files produced by the run; if `partial`, the files produced by the last completed run are
+		// carried over.
+		Commit(outputs []Output, partial bool) error
+		// FindOrphans returns the files produced by the last completed run that are no longer produced by any job;
+		// whether they remain on disk is for the caller to establish.
+		//
+		// `outputs` are the files produced by the current run. `ok` is false if no run was completed (e.g. a fresh
+		// checkout, as the database is local), in which case orphans cannot be established.
+		FindOrphans(outputs []Output) (orphans []Output, ok bool, err error)
 	}
 
-	hasUpdated = core.ScopeJobOverride
+	// Output represents a file produced by a job.
+	Output struct {
+		Scope   string
+		Package string
+		// Absolute path of the file; it is recorded relative to the current working directory.
+		Path string
+	}
 
 	diagnostics struct {
+		mu         *sync.Mutex
 		logger     slog.INamedLogger
 		repository IRepository
 
+		// readOnly prevents the recording of runs and snapshots (e.g. plan mode).
+		readOnly bool
+		// restrictedMap represents the packages snapshots are recorded for; all if nil (see `Restrict`).
+		restrictedMap  map[string]bool
+		runID          int64
+		cwd            string
 		resultsMap     map[string]*core.ScopeJobOverride
 		pkgsMap        map[string]core.Package
 		pkgsLastModMap map[string]int64
@@ -34,8 +68,13 @@ type (
 	}
 )
 
-func New(logger slog.ILogger, db db.IDatabase) IDiagnostics {
+// New returns a new implementation of `IDiagnostics`.
+//
+// When `readOnly` is set, changes are still detected but nothing is recorded.
+func New(logger slog.ILogger, db db.IDatabase, readOnly bool) IDiagnostics {
 	return &diagnostics{
+		mu:         &sync.Mutex{},
+		readOnly:   readOnly,
 		logger:     slog.NewNamed(logger, "diagnostics", slog.None),
 		repository: newRepository(logger, db),
 		resultsMap: map[string]*core.ScopeJobOverride{},
@@ -43,30 +82,96 @@ func New(logger slog.ILogger, db db.IDatabase) IDiagnostics {
 	}
 }
 
-func (d *diagnostics) Prepare(spec *core.Spec) error {
+func (d *diagnostics) Prepare(spec *core.Spec, args []byte) (err error) {
 	d.logger.Log("prepare", "msg", "preparing diagnostics module")
 
 	// -> Seed the database.
-	if err := d.repository.SeedDB(); err != nil {
-		return err
+	if err = d.repository.SeedDB(); err != nil {
+		return
+	}
+	// -> Record the current run; its snapshots are disregarded until committed.
+	if !d.readOnly {
+		if d.runID, err = d.repository.InsertRun(context.Background(), args); err != nil {
+			return
+		}
 	}
 
 	// -> Prepare the diagnostics module.
+	d.cwd = spec.Metadata.Cwd
 	d.pkgsLastModMap = spec.Metadata.PkgsLastModifiedMap
 	for _, pkg := range spec.Pkgs {
-		d.pkgsMap[pkg.Name] = *pkg
+		d.pkgsMap[pkg.Path] = *pkg
+	}
+	return
+}
+
+func (d *diagnostics) Commit(outputs []Output, partial bool) error {
+	if d.readOnly {
+		return nil
+	}
+	d.logger.Log("commit", "msg", "marking run as completed", "run_id", d.runID, "outputs", len(outputs))
+
+	ctx := context.Background()
+	rel := slice.Map(outputs, func(o Output) Output {
+		o.Path = strings.TrimPrefix(strings.Replace(o.Path, d.cwd, "", 1), "/")
+		return o
+	})
+	if partial {
+		prev, err := d.repository.FindOutputs(ctx)
+		if err != nil {
+			return err
+		}
+		seenMap := make(map[string]bool, len(rel))
+		for _, o := range rel {
+			seenMap[o.Path] = true
+		}
+		rel = append(rel, slice.Filter(prev, func(o Output) bool { return !seenMap[o.Path] })...)
+	}
+	if err := d.repository.InsertOutputs(ctx, d.runID, rel); err != nil {
+		return err
 	}
-	return nil
+	return d.repository.CompleteRun(ctx, d.runID)
 }
 
-func (d *diagnostics) Verify(overrOn map[string]core.ScopeJobOverride) (bool, error) {
-	_ = func(msg string, lines ...any) {
-		linesCopy := make([]any, 2+len(lines))
-		linesCopy = append(linesCopy, "msg", msg)
-		linesCopy = append(linesCopy, lines...)
-		d.logger.Log("verify", linesCopy...)
+func (d *diagnostics) FindOrphans(outputs []Output) ([]Output, bool, error) {
+	ctx := context.Background()
+	if ok, err := d.repository.HasCompletedRun(ctx); err != nil || !ok {
+		return nil, false, err
+	}
+	prev, err := d.repository.FindOutputs(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+
+	seenMap := make(map[string]bool, len(outputs))
+	for _, o := range outputs {
+		seenMap[o.Path] = true
 	}
-	hasChanged := func(pkg string, hasChanged, overr core.ScopeJobOverride) bool {
+	orphans := make([]Output, 0)
+	for _, o := range prev {
+		o.Path = d.cwd + "/" + o.Path
+		if !seenMap[o.Path] {
+			orphans = append(orphans, o)
+		}
+	}
+	return orphans, true, nil
+}
+
+func (d *diagnostics) Restrict(pkgs []string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.restrictedMap = make(map[string]bool, len(pkgs))
+	for _, p := range pkgs {
+		d.restrictedMap[p] = true
+	}
+}
+
+func (d *diagnostics) Verify(pkg *core.Package, overrOn map[string]core.ScopeJobOverride) (bool, error) {
+	d.mu.Lock() // Snapshots are performed once per package and run.
+	defer d.mu.Unlock()
+
+	hasChanged := func(hasChanged, overr core.ScopeJobOverride) bool {
 		if overr.Model && hasChanged.Model {
 			return true
 		}
@@ -76,18 +181,23 @@ func (d *diagnostics) Verify(overrOn map[string]core.ScopeJobOverride) (bool, er
 		return false
 	}
 
-	for pkg, overr := range overrOn {
-		// -> Check if the package has already been checked through snapshotting.
-		if hc, ok := d.resultsMap[pkg]; ok {
-			if ok = hasChanged(pkg, *hc, overr); ok {
-				return true, nil
-			}
-		} else {
-			// -> Perform snapshotting.
-			if err := d.performSnapshot(pkg, overr); err != nil {
-				return false, err
+	for key, overr := range overrOn {
+		pkgs, err := d.resolve(key, pkg)
+		if err != nil {
+			return false, err
+		}
+		for _, p := range pkgs {
+			// -> Check if the package has already been checked through snapshotting.
+			hc, ok := d.resultsMap[p]
+			if !ok {
+				// -> Perform snapshotting.
+				if hc, err = d.performSnapshot(p); err != nil {
+					return false, err
+				}
+				d.resultsMap[p] = hc
 			}
-			if ok = hasChanged(pkg, *d.resultsMap[pkg], overr); ok {
+			if hasChanged(*hc, overr) {
+				d.logger.Log("verify", "msg", "change detected", "package", p)
 				return true, nil
 			}
 		}
@@ -95,68 +205,69 @@ func (d *diagnostics) Verify(overrOn map[string]core.ScopeJobOverride) (bool, er
 	return false, nil // No changes detected.
 }
 
-func (d *diagnostics) performSnapshot(pkgS string, overr core.ScopeJobOverride) error {
-	d.resultsMap[pkgS] = &core.ScopeJobOverride{}
-
-	performSnapshot := func(ctx context.Context, pi int) error {
-		// This function is responsible for comparing the old and current snapshots for a given package and property.
-		// If the snapshots are different, the property has changed and the function will return true.
-		hasChanged := func(pi int) (bool, error) {
-			// [1] Get old snapshot.
-			s, err := d.repository.FindOne(ctx, pkgS, pi)
-			if err != nil {
-				return false, err
+// resolve returns the package paths targeted by the given `override-on` key.
+func (d *diagnostics) resolve(key string, pkg *core.Package) ([]string, error) {
+	if key == core.OverrideOnWildcard {
+		// -> Unique jobs are not bound to a package; consider them all.
+		if pkg == nil {
+			names := make([]string, 0, len(d.pkgsMap))
+			for name := range d.pkgsMap {
+				names = append(names, name)
 			}
+			sort.Strings(names)
+			return names, nil
+		}
+		return []string{pkg.Path}, nil
+	}
+	if _, ok := d.pkgsMap[key]; !ok {
+		return nil, errors.Errorf("diagnostics: override-on references unknown package '%s'", key)
+	}
+	return []string{key}, nil
+}
 
-			pkg, nS :=
-				d.pkgsMap[pkgS],
-				&snapshot{LastModified: d.pkgsLastModMap[pkgS]}
-
-			// [2] Compare snapshots.
-			hasChanged := false
-			if s.LastModified != nS.LastModified {
-				// -> Perform local snapshot.
-				var hash uint64
-				switch pi {
-				case 0:
-					hash, err = d.hash(pkg.Models)
-				case 1:
-					hash, err = d.hash(pkg.Interface)
-				}
-				if err != nil {
-					return false, err
-				}
-				nS.Hash = int64(hash)
-				// -> Compare hashes.
-				hasChanged = s.Hash != nS.Hash
-			}
-			if pkgS != "\\*" {
-				if err = d.repository.InsertOne(ctx, pkgS, pi, *nS); err != nil {
-					return false, err
-				}
-			}
+// performSnapshot compares the current state of each property of the given package against the last snapshot
+// recorded by a completed run. A new snapshot is recorded against the current run whenever the package file has been
+// modified since.
+func (d *diagnostics) performSnapshot(pkgS string) (*core.ScopeJobOverride, error) {
+	ctx, res := context.Background(), &core.ScopeJobOverride{}
 
-			return hasChanged, nil
+	pkg := d.pkgsMap[pkgS]
+	for pi := range res.AsSlice() {
+		// [1] Get old snapshot.
+		s, err := d.repository.FindOne(ctx, pkgS, pi)
+		if err != nil {
+			return nil, err
 		}
-		if ok, err := hasChanged(pi); err != nil {
-			return err
-		} else {
-			if ok {
-				d.resultsMap[pkgS].Set(pi, true)
-			}
-			return nil
+
+		// [2] Compare snapshots; an unmodified file is assumed to be unchanged.
+		nS := &snapshot{LastModified: d.pkgsLastModMap[pkgS]}
+		if s != nil && s.LastModified == nS.LastModified {
+			continue
+		}
+		// -> Perform local snapshot.
+		var hash uint64
+		switch pi {
+		case 0:
+			hash, err = d.hash(pkg.Models)
+		case 1:
+			hash, err = d.hash(pkg.Interface)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "diagnostics: failed to hash pkg=%s, i=%d", pkgS, pi)
+		}
+		nS.Hash = int64(hash)
+		// -> Compare hashes; the absence of a baseline is considered a change.
+		if s == nil || s.Hash != nS.Hash {
+			res.Set(pi, true)
 		}
-	}
 
-	// -> Compare for each available property.
-	errg, ctx := errgroup.WithContext(context.Background())
-	for i, ok := range overr.AsSlice() {
-		if ok {
-			errg.Go(func() error { return performSnapshot(ctx, i) })
+		if !d.readOnly && (d.restrictedMap == nil || d.restrictedMap[pkgS]) {
+			if err = d.repository.InsertOne(ctx, d.runID, pkgS, pi, *nS); err != nil {
+				return nil, err
+			}
 		}
 	}
-
-	return errg.Wait()
+	return res, nil
 }
 
 func (d *diagnostics) hash(v interface{}) (uint64, error) {
@@ -0,0 +1,206 @@
+package diagnostics
+
+import (
+	"context"
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/db"
+	"github.com/maxzaleski/codegen/internal/slog"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-playground/assert"
+)
+
+func TestDiagnostics_Verify(t *testing.T) {
+	tmpDir, err := os.MkdirTemp("", "diagnostics_test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func(path string) { _ = os.RemoveAll(path) }(tmpDir)
+
+	l := slog.New(false, time.Time{})
+	dbc, err := db.New(l, tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = dbc.Conn().Close() }()
+
+	newSpec := func(desc string, lastMod int64) *core.Spec {
+		pkg := &core.Package{
+			Entity:    core.Entity{Name: "user"},
+			Path:      "account/user",
+			Interface: &core.Interface{Description: desc},
+		}
+		return &core.Spec{
+			Pkgs: []*core.Package{pkg},
+			Metadata: &core.Metadata{
+				Cwd:                 tmpDir,
+				PkgsLastModifiedMap: map[string]int64{pkg.Path: lastMod},
+			},
+		}
+	}
+	overrOn := map[string]core.ScopeJobOverride{core.OverrideOnWildcard: {Interface: true}}
+
+	// runWith executes a new run against the given spec.
+	runWith := func(d IDiagnostics, spec *core.Spec, commit bool) bool {
+		if err = d.Prepare(spec, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		ok, err := d.Verify(spec.Pkgs[0], overrOn)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if commit {
+			if err = d.Commit(nil, false); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return ok
+	}
+	run := func(spec *core.Spec, commit bool) bool {
+		return runWith(New(l, dbc, false), spec, commit)
+	}
+
+	t.Run("no baseline", func(t *testing.T) {
+		assert.Equal(t, run(newSpec("v1", 1), true), true)
+	})
+
+	t.Run("unchanged", func(t *testing.T) {
+		assert.Equal(t, run(newSpec("v1", 1), true), false)
+	})
+
+	t.Run("modified file with identical contents", func(t *testing.T) {
+		assert.Equal(t, run(newSpec("v1", 2), true), false)
+	})
+
+	t.Run("failed run does not advance baseline", func(t *testing.T) {
+		assert.Equal(t, run(newSpec("v2", 3), false), true)
+		assert.Equal(t, run(newSpec("v2", 3), true), true)
+		assert.Equal(t, run(newSpec("v2", 3), true), false)
+	})
+
+	t.Run("read-only run does not advance baseline", func(t *testing.T) {
+		assert.Equal(t, runWith(New(l, dbc, true), newSpec("v3", 4), true), true)
+		assert.Equal(t, run(newSpec("v3", 4), true), true)
+	})
+
+	t.Run("restricted run only advances the baseline of listed packages", func(t *testing.T) {
+		d := New(l, dbc, false)
+		d.Restrict([]string{"billing"})
+		assert.Equal(t, runWith(d, newSpec("v4", 5), true), true)
+		assert.Equal(t, run(newSpec("v4", 5), true), true)
+
+		d = New(l, dbc, false)
+		d.Restrict([]string{"account/user"})
+		assert.Equal(t, runWith(d, newSpec("v5", 6), true), true)
+		assert.Equal(t, run(newSpec("v5", 6), true), false)
+	})
+
+	t.Run("unknown package", func(t *testing.T) {
+		d := New(l, dbc, false)
+		spec := newSpec("v2", 3)
+		if err = d.Prepare(spec, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		_, err = d.Verify(spec.Pkgs[0], map[string]core.ScopeJobOverride{"car": {Model: true}})
+		assert.NotEqual(t, err, nil)
+	})
+
+	t.Run("orphans", func(t *testing.T) {
+		spec := newSpec("v3", 4)
+		outputs := []Output{
+			{Scope: "scope", Package: "user", Path: tmpDir + "/user.go"},
+			{Scope: "scope", Package: "car", Path: tmpDir + "/car.go"},
+		}
+		for _, o := range outputs {
+			if err = os.WriteFile(o.Path, []byte{}, 0644); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		d := New(l, dbc, false)
+		if err = d.Prepare(spec, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		if err = d.Commit(outputs, false); err != nil {
+			t.Fatal(err)
+		}
+
+		d = New(l, dbc, true)
+		if err = d.Prepare(spec, []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		orphans, ok, err := d.FindOrphans(outputs[:1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, ok, true)
+		assert.Equal(t, orphans, outputs[1:])
+	})
+
+	// -> The database is local; a fresh checkout has none.
+	t.Run("orphans without completed run", func(t *testing.T) {
+		dbc, err := db.New(l, t.TempDir(), true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = dbc.Conn().Close() }()
+
+		d := New(l, dbc, true)
+		if err = d.Prepare(newSpec("v1", 1), []byte("{}")); err != nil {
+			t.Fatal(err)
+		}
+		orphans, ok, err := d.FindOrphans(nil)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, ok, false)
+		assert.Equal(t, len(orphans), 0)
+	})
+}
+
+func TestRepository_SeedDB(t *testing.T) {
+	tmpDir := t.TempDir()
+	l := slog.New(false, time.Time{})
+
+	// -> Database created by a previous version, prior to schema versioning.
+	dbc, err := db.New(l, tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, q := range []string{
+		`CREATE TABLE runs (id INTEGER PRIMARY KEY AUTOINCREMENT, arguments JSON NOT NULL);`,
+		`CREATE TABLE snapshots (id INTEGER PRIMARY KEY AUTOINCREMENT, run_id INTEGER NOT NULL, package TEXT NOT NULL);`,
+	} {
+		if _, err = dbc.Conn().Exec(q); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = dbc.Conn().Close()
+
+	// -> Read-only databases may not be recreated.
+	dbc, err = db.New(l, tmpDir, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotEqual(t, newRepository(l, dbc).SeedDB(), nil)
+	_ = dbc.Conn().Close()
+
+	dbc, err = db.New(l, tmpDir, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = dbc.Conn().Close() }()
+	assert.Equal(t, dbc.RequiresSeed(), false)
+
+	r := newRepository(l, dbc)
+	assert.Equal(t, r.SeedDB(), nil)
+	runID, err := r.InsertRun(context.Background(), []byte("{}"))
+	assert.Equal(t, err, nil)
+	assert.Equal(t, r.CompleteRun(context.Background(), runID), nil)
+
+	// -> Up-to-date databases are left untouched.
+	assert.Equal(t, r.SeedDB(), nil)
+	var n int
+	assert.Equal(t, dbc.Conn().QueryRow(`SELECT COUNT(*) FROM runs;`).Scan(&n), nil)
+	assert.Equal(t, n, 1)
+}
@@ -3,6 +3,7 @@ package diagnostics
 import (
 	"context"
 	"database/sql"
+	"fmt"
 	"github.com/maxzaleski/codegen/internal/db"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/pkg/errors"
@@ -11,8 +12,19 @@ import (
 type (
 	IRepository interface {
 		SeedDB() error
+		// InsertRun records the start of a new run and returns its identifier.
+		InsertRun(ctx context.Context, args []byte) (int64, error)
+		// CompleteRun marks the given run as completed; only snapshots of completed runs are considered as baseline.
+		CompleteRun(ctx context.Context, runID int64) error
+		// FindOne returns the latest snapshot recorded by a completed run, or nil if none exists.
 		FindOne(ctx context.Context, pkg string, pi int) (*snapshot, error)
-		InsertOne(ctx context.Context, pkg string, pi int, s snapshot) error
+		InsertOne(ctx context.Context, runID int64, pkg string, pi int, s snapshot) error
+		// InsertOutputs records the files produced by the given run.
+		InsertOutputs(ctx context.Context, runID int64, os []Output) error
+		// FindOutputs returns the files produced by the last completed run.
+		FindOutputs(ctx context.Context) ([]Output, error)
+		// HasCompletedRun returns true if a run was completed, as to tell apart a first run from one without outputs.
+		HasCompletedRun(ctx context.Context) (bool, error)
 	}
 
 	repository struct {
@@ -21,6 +33,11 @@ type (
 	}
 )
 
+// schemaVersion is the version of the database schema.
+//
+// An outdated database is recreated; it only holds diagnostics, the loss of which amounts to a first run.
+const schemaVersion = 2
+
 func newRepository(logger slog.ILogger, db db.IDatabase) IRepository {
 	return &repository{
 		db:     db,
@@ -28,13 +45,43 @@ func newRepository(logger slog.ILogger, db db.IDatabase) IRepository {
 	}
 }
 
+func (r *repository) InsertRun(ctx context.Context, args []byte) (int64, error) {
+	const q = `
+INSERT into runs (arguments) 
+VALUES ($1);
+`
+	res, err := r.db.ExecContext(ctx, q, string(args))
+	if err != nil {
+		return 0, errors.Wrap(err, "diagnostics: failed to insert run")
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, errors.Wrap(err, "diagnostics: failed to retrieve run id")
+	}
+	return id, nil
+}
+
+func (r *repository) CompleteRun(ctx context.Context, runID int64) error {
+	const q = `
+UPDATE runs 
+SET completed_at = CURRENT_TIMESTAMP 
+WHERE id = $1;
+`
+	if _, err := r.db.ExecContext(ctx, q, runID); err != nil {
+		return errors.Wrapf(err, "diagnostics: failed to complete run id=%d", runID)
+	}
+	return nil
+}
+
 func (r *repository) FindOne(ctx context.Context, pkg string, pi int) (*snapshot, error) {
 	const q = `
-SELECT last_modified, 
-       hash 
-FROM snapshots 
-WHERE (package, property_index) = ($1, $2)
-ORDER BY created_at
+SELECT s.last_modified, 
+       s.hash 
+FROM snapshots s
+INNER JOIN runs r ON r.id = s.run_id
+WHERE (s.package, s.property_index) = ($1, $2) 
+  AND r.completed_at IS NOT NULL
+ORDER BY s.id DESC
 LIMIT 1;
 `
 	rows, err := r.db.QueryContext(ctx, q, pkg, pi)
@@ -43,54 +90,138 @@ LIMIT 1;
 	}
 	defer func(rows *sql.Rows) { _ = rows.Close() }(rows)
 
-	var s snapshot
+	var s *snapshot
 	for rows.Next() {
+		s = &snapshot{}
 		if err = rows.Scan(&s.LastModified, &s.Hash); err != nil {
 			return nil, err
 		}
 	}
 
-	return &s, nil
+	return s, rows.Err()
 }
 
-func (r *repository) InsertOne(ctx context.Context, pkg string, pi int, s snapshot) error {
+func (r *repository) InsertOne(ctx context.Context, runID int64, pkg string, pi int, s snapshot) error {
 	const q = `
-INSERT into snapshots (package, 
+INSERT into snapshots (run_id,
+					   package, 
 					   property_index, 
 					   last_modified, 
 					   hash) 
-VALUES ($1, $2, $3, $4);
+VALUES ($1, $2, $3, $4, $5);
 `
-	_, err := r.db.ExecContext(ctx, q, pkg, pi, s.LastModified, s.Hash)
+	_, err := r.db.ExecContext(ctx, q, runID, pkg, pi, s.LastModified, s.Hash)
 	if err != nil {
 		return errors.Wrapf(err, "diagnostics: failed to insert snapshot for pkg=%s, i=%d", pkg, pi)
 	}
 	return nil
 }
 
+func (r *repository) InsertOutputs(ctx context.Context, runID int64, os []Output) error {
+	const q = `
+INSERT into outputs (run_id,
+					 scope,
+					 package,
+					 path)
+VALUES ($1, $2, $3, $4);
+`
+	tx, err := r.db.Conn().BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "diagnostics: failed to begin transaction")
+	}
+	for _, o := range os {
+		if _, err = tx.ExecContext(ctx, q, runID, o.Scope, o.Package, o.Path); err != nil {
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return errors.Wrap(rbErr, "diagnostics: failed to rollback transaction")
+			}
+			return errors.Wrapf(err, "diagnostics: failed to insert output for path=%s", o.Path)
+		}
+	}
+	if err = tx.Commit(); err != nil {
+		err = errors.Wrap(err, "diagnostics: failed to commit transaction")
+	}
+	return err
+}
+
+func (r *repository) FindOutputs(ctx context.Context) ([]Output, error) {
+	const q = `
+SELECT o.scope,
+       o.package,
+       o.path
+FROM outputs o
+WHERE o.run_id = (SELECT MAX(r.id) FROM runs r WHERE r.completed_at IS NOT NULL)
+ORDER BY o.path;
+`
+	rows, err := r.db.QueryContext(ctx, q)
+	if err != nil {
+		return nil, errors.Wrap(err, "diagnostics: failed to query outputs")
+	}
+	defer func(rows *sql.Rows) { _ = rows.Close() }(rows)
+
+	os := make([]Output, 0)
+	for rows.Next() {
+		var o Output
+		if err = rows.Scan(&o.Scope, &o.Package, &o.Path); err != nil {
+			return nil, err
+		}
+		os = append(os, o)
+	}
+
+	return os, rows.Err()
+}
+
+func (r *repository) HasCompletedRun(ctx context.Context) (bool, error) {
+	const q = `
+SELECT EXISTS(SELECT 1 FROM runs r WHERE r.completed_at IS NOT NULL);
+`
+	var ok bool
+	if err := r.db.Conn().QueryRowContext(ctx, q).Scan(&ok); err != nil {
+		return false, errors.Wrap(err, "diagnostics: failed to query runs")
+	}
+	return ok, nil
+}
+
 func (r *repository) SeedDB() error {
 	log := func(msg string) {
 		r.logger.Log("seed", "msg", msg)
 	}
 
+	qs := make([]string, 0)
 	if !r.db.RequiresSeed() {
-		log("seeding not required")
-		return nil
+		var v int
+		if err := r.db.Conn().QueryRow("PRAGMA user_version;").Scan(&v); err != nil {
+			return errors.Wrap(err, "diagnostics: failed to query schema version")
+		}
+		if v == schemaVersion {
+			log("seeding not required")
+			return nil
+		}
+		if r.db.ReadOnly() {
+			return errors.Errorf("diagnostics: database schema is outdated (v%d); generate once to upgrade it", v)
+		}
+
+		log("schema outdated, recreating database")
+		qs = append(qs,
+			`DROP TABLE IF EXISTS outputs;`,
+			`DROP TABLE IF EXISTS snapshots;`,
+			`DROP TABLE IF EXISTS runs;`,
+		)
 	}
 
 	log("seeding database")
 
-	qs := []string{
+	qs = append(qs, []string{
 		`
 		CREATE TABLE IF NOT EXISTS runs (
 		   id INTEGER PRIMARY KEY AUTOINCREMENT,
            arguments JSON NOT NULL,
-		   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+		   created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		   completed_at TIMESTAMP
 		);`,
 		`
 		CREATE TABLE IF NOT EXISTS snapshots (
 		   id INTEGER PRIMARY KEY AUTOINCREMENT,
-		   run_id INTEGER NOT NULL,
+		   run_id INTEGER NOT NULL REFERENCES runs (id),
 		   package TEXT NOT NULL,
 		   property_index INTEGER NOT NULL,
 		   last_modified INTEGER NOT NULL,
@@ -98,7 +229,17 @@ func (r *repository) SeedDB() error {
            created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 		);`,
 		`CREATE INDEX IF NOT EXISTS idx_snapshots_package_property_index ON snapshots (package, property_index);`,
-	}
+		`
+		CREATE TABLE IF NOT EXISTS outputs (
+		   id INTEGER PRIMARY KEY AUTOINCREMENT,
+		   run_id INTEGER NOT NULL REFERENCES runs (id),
+		   scope TEXT NOT NULL,
+		   package TEXT NOT NULL,
+		   path TEXT NOT NULL
+		);`,
+		`CREATE INDEX IF NOT EXISTS idx_outputs_run_id ON outputs (run_id);`,
+		fmt.Sprintf(`PRAGMA user_version = %d;`, schemaVersion),
+	}...)
 
 	tx, err := r.db.Conn().Begin()
 	if err != nil {
@@ -106,8 +247,8 @@ func (r *repository) SeedDB() error {
 	}
 	for i, q := range qs {
 		if _, err = tx.ExecContext(context.Background(), q); err != nil {
-			if err = tx.Rollback(); err != nil {
-				return errors.Wrap(err, "diagnostics: failed to rollback transaction")
+			if rbErr := tx.Rollback(); rbErr != nil {
+				return errors.Wrap(rbErr, "diagnostics: failed to rollback transaction")
 			}
 			return errors.Wrapf(err, "diagnostics: failed to seed database: stmt[%d]", i)
 		}
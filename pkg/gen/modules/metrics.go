@@ -10,25 +10,79 @@ type (
 		GetJobsMetrics() map[string]interface{}
 		CaptureWorkUnit(m MetricWorkUnit)
 		GetWorkMetrics() map[int]int
+		CaptureTemplateCache(m MetricTemplateCache)
+		// GetTemplateCacheMetrics returns the number of hits and misses of the template cache.
+		GetTemplateCacheMetrics() (hits, misses int)
+		CaptureOrphanCheck(m MetricOrphanCheck)
+		// GetOrphanCheckMetrics returns true if orphaned files were established (check mode).
+		GetOrphanCheckMetrics() (checked bool)
 	}
 
 	MetricJob struct {
 		FileAbsolutePath string
-		FileCreated      bool
+		// FileCreated indicates whether the file was (or, in plan mode, would be) written to disk.
+		FileCreated bool
+		Outcome     FileOutcome
+		// Diff is the unified diff between the existing and rendered file (diff mode only).
+		Diff string
 	}
 
+	// FileOutcome represents the outcome of a job in regard to its output file.
+	FileOutcome string
+
 	MetricWorkUnit struct {
 		WorkerID int
 	}
 
+	MetricTemplateCache struct {
+		// Hit indicates whether the template set of a job was found in the cache (see `ITemplateCache`).
+		Hit bool
+	}
+
+	MetricOrphanCheck struct {
+		// Checked indicates whether orphaned files were established; they cannot be without a completed run recorded
+		// by the diagnostics database (see `IDiagnostics.FindOrphans`).
+		Checked bool
+	}
+
 	metrics struct {
 		mu *sync.Mutex
 
 		jobsMap map[string]interface{}
 		workMap map[int]int
+		// Represents the hits and misses of the template cache.
+		cacheHits, cacheMisses int
+		orphansChecked         bool
 	}
 )
 
+const (
+	FileOutcomeCreated     FileOutcome = "created"
+	FileOutcomeOverwritten FileOutcome = "overwritten"
+	FileOutcomeIgnored     FileOutcome = "already-exists"
+
+	// Check mode outcomes.
+	FileOutcomeUpToDate FileOutcome = "up-to-date"
+	FileOutcomeMissing  FileOutcome = "missing"
+	FileOutcomeModified FileOutcome = "modified"
+	FileOutcomeOrphaned FileOutcome = "orphaned"
+
+	// Clean mode outcomes.
+	FileOutcomeRemoved FileOutcome = "removed"
+)
+
+// IsDrifted returns true if the outcome denotes a file that is out of date (check mode).
+func (o FileOutcome) IsDrifted() bool {
+	switch o {
+	case FileOutcomeMissing,
+		FileOutcomeModified,
+		FileOutcomeOrphaned:
+		return true
+	default:
+		return false
+	}
+}
+
 // NewMetrics returns a new instance of `IMetrics`.
 func NewMetrics() IMetrics {
 	return &metrics{
@@ -75,3 +129,35 @@ func (ms *metrics) CaptureWorkUnit(m MetricWorkUnit) {
 func (ms *metrics) GetWorkMetrics() map[int]int {
 	return ms.workMap
 }
+
+func (ms *metrics) CaptureTemplateCache(m MetricTemplateCache) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	if m.Hit {
+		ms.cacheHits++
+	} else {
+		ms.cacheMisses++
+	}
+}
+
+func (ms *metrics) GetTemplateCacheMetrics() (int, int) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.cacheHits, ms.cacheMisses
+}
+
+func (ms *metrics) CaptureOrphanCheck(m MetricOrphanCheck) {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	ms.orphansChecked = m.Checked
+}
+
+func (ms *metrics) GetOrphanCheckMetrics() bool {
+	ms.mu.Lock()
+	defer ms.mu.Unlock()
+
+	return ms.orphansChecked
+}
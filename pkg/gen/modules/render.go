@@ -0,0 +1,53 @@
+package modules
+
+import "github.com/maxzaleski/codegen/internal/core"
+
+type (
+	// RenderContext represents the data templates are executed with (i.e. `.`); it is the same for all jobs, unique
+	// or not.
+	RenderContext struct {
+		// Package represents the package the job is executed for; nil for unique jobs.
+		Package *core.Package
+		// Model represents the model the job is executed for; nil unless its file name refers to the 'model' token.
+		Model *core.Model
+		// Packages represents all packages of the specification.
+		Packages []*core.Package
+		Scope    RenderScope
+		Job      RenderJob
+		Output   RenderOutput
+		// Config represents the configuration of the generation (i.e. '.codegen/config.yaml').
+		Config *core.Config
+		// Vars represents the variables defined by the user, as per the job (see `core.MergeVars`).
+		Vars core.Vars
+	}
+
+	// RenderScope represents the scope a job belongs to.
+	RenderScope struct {
+		Key string
+		// Domain represents the domain of the scope; either 'pkg' or 'http'.
+		Domain string
+		// Output represents the output directory of the scope, relative to the working directory.
+		Output string
+		Inline bool
+	}
+
+	// RenderJob represents the job being executed.
+	RenderJob struct {
+		// Key represents the key of the job, as declared by the configuration.
+		Key      string
+		Unique   bool
+		Override bool
+	}
+
+	// RenderOutput represents the file produced by a job.
+	RenderOutput struct {
+		// Name represents the name of the file, extension included (e.g. 'user.go').
+		Name string
+		// Ext represents the extension of the file, without the leading dot (e.g. 'go').
+		Ext string
+		// Dir represents the directory of the file, relative to the working directory (e.g. 'internal/domain/user').
+		Dir string
+		// Path represents the path of the file, relative to the working directory (e.g. 'internal/domain/user/user.go').
+		Path string
+	}
+)
@@ -2,94 +2,248 @@ package modules
 
 import (
 	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template"
+
 	"github.com/maxzaleski/codegen/internal/core"
 	"github.com/maxzaleski/codegen/internal/embeds"
-	"github.com/maxzaleski/codegen/internal/fs"
-	"github.com/maxzaleski/codegen/internal/lib/slice"
 	"github.com/maxzaleski/codegen/pkg/gen/partials"
+	"github.com/maxzaleski/codegen/pkg/vfs"
 	"github.com/pkg/errors"
-	"strings"
-	"text/template"
 )
 
 type (
 	ITemplateProcessor interface {
-		Exec(tts []core.ScopeJobTemplate, dtt bool, pkg *core.Package, dest, ext string, fm template.FuncMap) error
+		// Render renders the given templates in memory, executing them with `data`.
+		Render(tts []core.ScopeJobTemplate, dtt bool, data *RenderContext, ext string, fm template.FuncMap) ([]byte, error)
+		// Write writes the rendered bytes to `dest`, creating its directory if necessary.
+		Write(b []byte, dest string) error
 	}
 
 	templateProcessor struct {
-		pkgs []*core.Package
+		// Represents the custom types declared by the configuration (see `core.Config.Types`).
+		types   map[string]map[string]string
+		md      *core.Metadata
+		fsys    vfs.FS
+		cache   ITemplateCache
+		metrics IMetrics
 	}
 )
 
-func NewTemplateProcessor(pkgs []*core.Package) ITemplateProcessor {
+// NewTemplateProcessor returns a new instance of `ITemplateProcessor`; template sets are compiled once, and shared
+// through `cache` until one of their files changes.
+func NewTemplateProcessor(
+	types map[string]map[string]string,
+	md *core.Metadata,
+	fsys vfs.FS,
+	cache ITemplateCache,
+	metrics IMetrics,
+) ITemplateProcessor {
 	return &templateProcessor{
-		pkgs: pkgs,
+		types:   types,
+		md:      md,
+		fsys:    fsys,
+		cache:   cache,
+		metrics: metrics,
 	}
 }
 
-func (tp *templateProcessor) Exec(
-	tts []core.ScopeJobTemplate, dtt bool, pkg *core.Package, dest, ext string, fm template.FuncMap,
-) error {
+func (tp *templateProcessor) Render(
+	tts []core.ScopeJobTemplate, dtt bool, data *RenderContext, ext string, fm template.FuncMap,
+) ([]byte, error) {
+	tt, name, err := tp.parse(tts, dtt, ext, fm)
+	if err != nil {
+		return nil, err
+	}
+	return tp.execute(tt, name, data)
+}
+
+// parse returns the template set of a job, and the name of its primary template.
+func (tp *templateProcessor) parse(
+	tts []core.ScopeJobTemplate, dtt bool, ext string, fm template.FuncMap,
+) (*template.Template, string, error) {
 	// [dev] Execute an empty template.
 	if dtt {
 		tt, err := template.ParseFS(embeds.FS, "templates/empty.tmpl")
 		if err != nil {
 			panic("binary corrupted")
 		}
-		return tp.write(tt, pkg, dest, ext)
+		return tt, tt.Name(), nil
+	}
+	if len(tts) == 0 {
+		return nil, "", errors.New("no templates were specified")
 	}
 
-	// 1. Define primary and secondary templates.
-	parsable, ptt := make([]string, len(tts)), ""
-	parsable = append(parsable,
-		slice.Map(
-			// Filter out the primary template.
-			slice.Filter(tts, func(t core.ScopeJobTemplate) bool { return !t.Primary }),
-			// Map to template names.
-			func(t core.ScopeJobTemplate) string { return t.Name })...,
-	)
+	// 1. Define the primary template; the first template is primary unless specified otherwise.
+	ptt := tts[0].Name
+	names := make([]string, 0, len(tts))
+	for _, t := range tts {
+		if t.Primary {
+			ptt = t.Name
+		}
+		names = append(names, t.Name)
+	}
+	primary, _, _ := tp.resolve(ptt)
+
+	// 2. Retrieve the template set from the cache; it is compiled upon the first job referring to it.
+	tt, _, hit, err := tp.cache.Get(tp.key(ext, fm, names...), func() (*template.Template, []string, error) {
+		return tp.compile(tts, ext, fm)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	tp.metrics.CaptureTemplateCache(MetricTemplateCache{Hit: hit})
+	return tt, primary, nil
+}
 
-	// 2. Parse primary template; defines base for all future inclusions.
-	tt, err := template.ParseFiles(ptt)
+// compile compiles the template set of a job: a clone of the library of the extension (see `library`), including the
+// given templates.
+func (tp *templateProcessor) compile(
+	tts []core.ScopeJobTemplate, ext string, fm template.FuncMap,
+) (*template.Template, []string, error) {
+	// 1. Clone the library of the extension; it already includes the templates located within the library directory.
+	lib, libFiles, _, err := tp.cache.Get(tp.key(ext, fm), func() (*template.Template, []string, error) {
+		return tp.library(ext, fm)
+	})
 	if err != nil {
-		return errors.Wrapf(err, "failed to parse primary template '%s'", ptt)
+		return nil, nil, err
 	}
-	// -> Include user-defined secondary templates.
-	if tt, err = tt.ParseFiles(parsable...); err != nil {
-		return errors.Wrap(err, "failed to parse secondary templates")
+	tt, err := lib.Clone()
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to clone templates library")
 	}
+	files := append(make([]string, 0, len(libFiles)+len(tts)), libFiles...)
 
-	// 3. Append custom functions to template.
-	tt.Funcs(fm)
-	defer func() {
-		if r := recover(); r != nil {
-			panic(errors.Wrap(r.(error), "failed to append custom functions to template"))
+	// 2. Include the templates located outside the library directory; as per `template.ParseFiles`, they are named
+	// after their base name, and take precedence over the library.
+	for _, t := range tts {
+		name, path, inLib := tp.resolve(t.Name)
+		if inLib && tt.Lookup(name) == nil {
+			return nil, nil, errors.Errorf("template '%s' does not exist", t.Name)
+		}
+		if !inLib {
+			b, err := os.ReadFile(path)
+			if err == nil {
+				_, err = tt.New(name).Parse(string(b))
+			}
+			if err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to parse template '%s'", t.Name)
+			}
+			files = append(files, path)
 		}
-	}()
+	}
+	return tt, files, nil
+}
 
-	// 4. Write template to disk.
-	return tp.write(tt, pkg, dest, ext)
+// key returns the cache key of the template set comprising the given templates; the library of an extension is keyed
+// without any.
+//
+// Template sets are specific to the library directory, the extension, the custom types (see `partials.GetByExtension`),
+// and `fm`, as identified by its address: functions cannot be compared, and closures share the address of their code
+// regardless of the values they capture. A FuncMap must therefore not be modified once used.
+func (tp *templateProcessor) key(ext string, fm template.FuncMap, names ...string) string {
+	return strings.Join([]string{
+		tp.md.TemplatesDir(),
+		ext,
+		fmt.Sprint(tp.types), // -> Maps are printed in key order.
+		fmt.Sprintf("%p", fm),
+		strings.Join(names, ","),
+	}, "\x00")
 }
 
-func (tp *templateProcessor) write(tt *template.Template, pkg *core.Package, dest, ext string) error {
-	funcs := partials.GetByExtension(ext)
+// library returns the templates library of the given extension, along with the files it was read from.
+//
+// The library comprises the embedded templates of the extension (see `embeds.Link`), and the templates located
+// within the library directory, named after their path relative to it (e.g. 'partials/header.tmpl'); the 'partials'
+// directory is parsed first, as for other templates to override its definitions.
+func (tp *templateProcessor) library(ext string, fm template.FuncMap) (*template.Template, []string, error) {
+	// -> Functions of other extensions are stubbed, as the library is shared by all extensions.
+	tt := template.New("").
+		Funcs(partials.Unavailable(ext)).
+		Funcs(partials.GetByExtension(ext, tp.types)).
+		Funcs(fm)
+	tt, err := embeds.Link(tt, ext)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := tp.parseDir(tt)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tt, files, nil
+}
+
+// parseDir parses the templates of the library directory into `tt`; it returns the files and directories read, as for
+// the addition or removal of templates to be detected.
+func (tp *templateProcessor) parseDir(tt *template.Template) ([]string, error) {
+	dir := tp.md.TemplatesDir()
+	files, names := []string{dir}, make([]string, 0)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir && errors.Is(err, fs.ErrNotExist) {
+				return filepath.SkipDir // The library is optional.
+			}
+			return err
+		}
+		if d.IsDir() && path != dir {
+			files = append(files, path)
+		}
+		if !d.IsDir() && filepath.Ext(path) == ".tmpl" {
+			rel, _ := filepath.Rel(dir, path)
+			names = append(names, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read templates library at '%s'", dir)
+	}
 
-	var (
-		buf  bytes.Buffer
-		data any
-	)
-	data = tp.pkgs
-	if pkg != nil {
-		data = pkg
+	partialsDir := core.TemplatesPartialsDir + "/"
+	sort.SliceStable(names, func(i, j int) bool {
+		pi, pj := strings.HasPrefix(names[i], partialsDir), strings.HasPrefix(names[j], partialsDir)
+		if pi != pj {
+			return pi
+		}
+		return names[i] < names[j]
+	})
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		b, err := os.ReadFile(path)
+		if err == nil {
+			_, err = tt.New(name).Parse(string(b))
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse template '%s'", name)
+		}
+		files = append(files, path)
 	}
+	return files, nil
+}
 
-	if err := tt.Funcs(funcs).Execute(&buf, data); err != nil {
-		ts := strings.Join(slice.Map(tt.Templates(), func(t *template.Template) string { return t.Name() }), ", ")
-		return errors.Wrapf(err, "failed to execute templates '%s'", ts)
+// resolve returns the name of the given template within a template set, its location (see
+// `core.Metadata.TemplatePath`), and whether it is located within the library directory; templates of the library
+// are named after their path relative to it.
+func (tp *templateProcessor) resolve(name string) (string, string, bool) {
+	path := tp.md.TemplatePath(name)
+	if rel, err := filepath.Rel(tp.md.TemplatesDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		return filepath.ToSlash(rel), path, true
 	}
-	if err := fs.CreateFile(dest, buf.Bytes()); err != nil {
-		return err
+	return filepath.Base(path), path, false
+}
+
+func (tp *templateProcessor) execute(tt *template.Template, name string, data *RenderContext) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := tt.ExecuteTemplate(&buf, name, data); err != nil {
+		return nil, errors.Wrapf(err, "failed to execute template '%s'", name)
 	}
-	return nil
+	return bytes.TrimSpace(buf.Bytes()), nil
+}
+
+func (tp *templateProcessor) Write(b []byte, dest string) error {
+	return tp.fsys.WriteFile(dest, b)
 }
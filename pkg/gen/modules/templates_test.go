@@ -0,0 +1,46 @@
+package modules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+func TestTemplateProcessor_Render(t *testing.T) {
+	md := &core.Metadata{CodegenDir: t.TempDir()}
+	if err := os.MkdirAll(md.TemplatesDir(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(md.TemplatesDir(), "value.tmpl"), []byte("{{value}}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cache := NewTemplateCache()
+	tts := []core.ScopeJobTemplate{{Name: "value.tmpl"}}
+
+	// -> Closures share the address of their code; the FuncMap they belong to tells them apart.
+	funcMap := func(v string) template.FuncMap {
+		return template.FuncMap{"value": func() string { return v }}
+	}
+	for _, v := range []string{"a", "b"} {
+		tp := NewTemplateProcessor(nil, md, nil, cache, NewMetrics())
+		b, err := tp.Render(tts, false, &RenderContext{}, "go", funcMap(v))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), v)
+	}
+
+	// -> The template set of a FuncMap is compiled once.
+	fm, metrics := funcMap("c"), NewMetrics()
+	tp := NewTemplateProcessor(nil, md, nil, cache, metrics)
+	for i := 0; i < 2; i++ {
+		b, err := tp.Render(tts, false, &RenderContext{}, "go", fm)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, string(b), "c")
+	}
+	hits, misses := metrics.GetTemplateCacheMetrics()
+	assert.Equal(t, hits, 1)
+	assert.Equal(t, misses, 1)
+}
@@ -0,0 +1,265 @@
+package partials
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"unicode"
+
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+// goPartials returns the template functions of '.go' outputs:
+//
+// • identifier: the name of an entity, exported as per its scope (e.g. `{{identifier .Name .Scope}}`).
+// • receiver: the receiver name of a model's methods (e.g. 'User' => 'u').
+// • doc: the doc comment of an entity, from its description (e.g. `{{doc .Name .Description}}`).
+// • signature: the signature of a function, without the 'func' keyword; return parameters are unnamed (e.g.
+// 'Rename(name string) error').
+// • field: the declaration of a struct field, tagged as per its 'tags' addon (e.g. 'ID string `json:"id"`').
+// • fields: the declarations of the given properties, indented and aligned as per gofmt; one per line.
+// • tags: the struct tags of a property; empty if none.
+// • imports: the import block of a package; `base` represents the import path of the generated packages, to which
+// their path is appended (e.g. `{{imports .Package "example.com/app/internal/domain"}}`).
+func goPartials(l lib) map[string]interface{} {
+	g := &goLib{l}
+	return map[string]interface{}{
+		"identifier": goIdentifier,
+		"receiver":   goReceiver,
+		"doc":        goDoc,
+		"signature":  g.signature,
+		"field":      g.field,
+		"fields":     g.fields,
+		"tags":       goTags,
+		"imports":    g.imports,
+	}
+}
+
+type goLib struct {
+	lib
+}
+
+// goIdentifier returns `name`, exported if `scope` is public, and unexported if private or protected; unchanged
+// otherwise. Initialisms are unexported as a whole (e.g. 'ID' => 'id').
+func goIdentifier(name string, scope core.EntityScope) string {
+	if name == "" {
+		return name
+	}
+	r := []rune(name)
+	switch scope {
+	case core.EntityScopePublic:
+		r[0] = unicode.ToUpper(r[0])
+	case core.EntityScopePrivate, core.EntityScopeProtected:
+		if strings.ToUpper(name) == name {
+			return strings.ToLower(name)
+		}
+		r[0] = unicode.ToLower(r[0])
+	}
+	return string(r)
+}
+
+// goReceiver returns the receiver name of the methods of the given model (e.g. 'User' => 'u').
+func goReceiver(model string) string {
+	for _, r := range model {
+		return string(unicode.ToLower(r))
+	}
+	return "m"
+}
+
+// goDoc returns the doc comment of an entity; empty if it has no description.
+func goDoc(name, description string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return ""
+	}
+	lines := strings.Split(name+" "+description, "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight("// "+strings.TrimSpace(l), " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (g *goLib) signature(fn *core.Function) (string, error) {
+	params, err := g.params(fn.Params, true)
+	if err != nil {
+		return "", err
+	}
+	returns, err := g.params(fn.Returns, false)
+	if err != nil {
+		return "", err
+	}
+
+	s := goIdentifier(fn.Name, fn.Scope) + "(" + strings.Join(params, ", ") + ")"
+	switch len(returns) {
+	case 0:
+		return s, nil
+	case 1:
+		return s + " " + returns[0], nil
+	default:
+		return s + " (" + strings.Join(returns, ", ") + ")", nil
+	}
+}
+
+// params returns the declarations of the given parameters; names are omitted unless `named` is set.
+func (g *goLib) params(ps []*core.FnParameter, named bool) ([]string, error) {
+	decls := make([]string, 0, len(ps))
+	for i, p := range ps {
+		t, err := g.mapType(p.TypeRef)
+		if err != nil {
+			return nil, err
+		}
+		if named {
+			t = paramName(p, i) + " " + t
+		}
+		decls = append(decls, t)
+	}
+	return decls, nil
+}
+
+func (g *goLib) field(p *core.ModelProperty) (string, error) {
+	cells, err := g.fieldCells(p)
+	if err != nil {
+		return "", err
+	}
+	return strings.Join(cells, " "), nil
+}
+
+func (g *goLib) fields(ps []core.ModelProperty) (string, error) {
+	if len(ps) == 0 {
+		return "", nil
+	}
+	var b strings.Builder
+	// -> As per gofmt: names, types and tags are aligned across consecutive fields, padded by a single space.
+	tw := tabwriter.NewWriter(&b, 0, 8, 1, ' ', 0)
+	for i := range ps {
+		cells, err := g.fieldCells(&ps[i])
+		if err != nil {
+			return "", err
+		}
+		_, _ = fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	if err := tw.Flush(); err != nil {
+		return "", err
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	for i, l := range lines {
+		lines[i] = "\t" + l
+	}
+	return strings.Join(lines, "\n"), nil
+}
+
+// fieldCells returns the name, type and tags (if any) of the declaration of a struct field.
+func (g *goLib) fieldCells(p *core.ModelProperty) ([]string, error) {
+	t, err := g.mapType(p.TypeRef)
+	if err != nil {
+		return nil, err
+	}
+	cells := []string{goIdentifier(p.Name, p.Scope), t}
+	if tags := goTags(p); tags != "" {
+		cells = append(cells, tags)
+	}
+	return cells, nil
+}
+
+// goTags returns the struct tags of the given property, as per its 'tags' addon (e.g. `{json: id}`), sorted by key.
+func goTags(p *core.ModelProperty) string {
+	if p.Addons == nil {
+		return ""
+	}
+	tags := make(map[string]string)
+	switch v := (*p.Addons)["tags"].(type) {
+	case map[string]string:
+		tags = v
+	case map[string]interface{}:
+		for k, val := range v {
+			tags[k] = fmt.Sprint(val)
+		}
+	}
+	if len(tags) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		pairs = append(pairs, fmt.Sprintf("%s:%q", k, tags[k]))
+	}
+	return "`" + strings.Join(pairs, " ") + "`"
+}
+
+// imports returns the import block of the given package: the standard packages its types map to (e.g. 'time'), the
+// packages of custom types mapped by import path (e.g. 'uuid: {go: github.com/google/uuid.UUID}'), and the
+// generated packages it refers to (see `core.Package.Imports`). Returns an empty string if there are none.
+func (g *goLib) imports(pkg *core.Package, base string) string {
+	std, ext := make(map[string]bool), make(map[string]bool)
+	var walk func(t *core.TypeRef)
+	walk = func(t *core.TypeRef) {
+		if t == nil {
+			return
+		}
+		for _, a := range t.Args {
+			walk(a)
+		}
+		if t.Qualifier != "" || t.Package != nil {
+			return
+		}
+		// -> Custom types are only imported if mapped by import path; their package is otherwise unknown.
+		if s, ok := g.types[t.Name]["go"]; ok {
+			if i := strings.LastIndex(s, "."); i != -1 && strings.Contains(s[:i], "/") {
+				ext[strings.TrimLeft(s[:i], "*[]")] = true
+			}
+			return
+		}
+		if s := typeMaps["go"].Types[t.Name]; strings.Contains(s, ".") {
+			std[strings.TrimLeft(s[:strings.LastIndex(s, ".")], "*[]")] = true
+		}
+	}
+	fn := func(fn *core.Function) {
+		for _, p := range fn.Params {
+			walk(p.TypeRef)
+		}
+		for _, p := range fn.Returns {
+			walk(p.TypeRef)
+		}
+	}
+	for i := range pkg.Models {
+		m := &pkg.Models[i]
+		for j := range m.Properties {
+			walk(m.Properties[j].TypeRef)
+		}
+		for j := range m.Methods {
+			fn(&m.Methods[j])
+		}
+	}
+	if pkg.Interface != nil {
+		for _, m := range pkg.Interface.Methods {
+			fn(m)
+		}
+	}
+	for _, p := range pkg.Imports {
+		ext[path.Join(base, p.Path)] = true
+	}
+
+	groups := make([]string, 0, 2)
+	for _, set := range []map[string]bool{std, ext} {
+		if len(set) == 0 {
+			continue
+		}
+		paths := make([]string, 0, len(set))
+		for p := range set {
+			paths = append(paths, fmt.Sprintf("\t%q", p))
+		}
+		sort.Strings(paths)
+		groups = append(groups, strings.Join(paths, "\n"))
+	}
+	if len(groups) == 0 {
+		return ""
+	}
+	return "import (\n" + strings.Join(groups, "\n\n") + "\n)"
+}
@@ -0,0 +1,144 @@
+package partials
+
+import (
+	"go/format"
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+func TestGoIdentifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		scope    core.EntityScope
+		expected string
+	}{
+		{"findById", core.EntityScopePublic, "FindById"},
+		{"FindByID", core.EntityScopePrivate, "findByID"},
+		{"ID", core.EntityScopeProtected, "id"},
+		{"email", "", "email"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, goIdentifier(test.name, test.scope), test.expected)
+		})
+	}
+}
+
+func TestGoDoc(t *testing.T) {
+	assert.Equal(t, goDoc("User", ""), "")
+	assert.Equal(t, goDoc("User", "represents a user."), "// User represents a user.")
+	assert.Equal(t, goDoc("User", "represents a user.\n\nDeprecated: use Account."),
+		"// User represents a user.\n//\n// Deprecated: use Account.")
+}
+
+func TestGoLib(t *testing.T) {
+	ref := func(s string) *core.TypeRef {
+		r, err := core.ParseType(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	g := &goLib{lib{ext: "go", types: map[string]map[string]string{
+		"uuid":  {"go": "github.com/google/uuid.UUID"},
+		"money": {"go": "decimal.Decimal"},
+		"price": {"go": "*github.com/shopspring/decimal.Decimal"},
+		"blob":  {"go": "[]github.com/acme/storage.Chunk"},
+		"node":  {"go": "gopkg.in/yaml.v3.Node"},
+	}}}
+
+	t.Run("signature", func(t *testing.T) {
+		fn := &core.Function{
+			EntityWithScope: core.EntityWithScope{Entity: core.Entity{Name: "findByID"}, Scope: core.EntityScopePublic},
+			Params:          []*core.FnParameter{{Name: "id", TypeRef: ref("uuid")}, {TypeRef: ref("bool")}},
+			Returns:         []*core.ReturnParameter{{Name: "user", TypeRef: ref("User?")}, {TypeRef: ref("error")}},
+		}
+		s, err := g.signature(fn)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, s, "FindByID(id uuid.UUID, p2 bool) (*User, error)")
+
+		fn.Returns = fn.Returns[1:]
+		s, _ = g.signature(fn)
+		assert.Equal(t, s, "FindByID(id uuid.UUID, p2 bool) error")
+	})
+
+	t.Run("field", func(t *testing.T) {
+		p := &core.ModelProperty{
+			EntityWithScope: core.EntityWithScope{Entity: core.Entity{Name: "id"}, Scope: core.EntityScopePublic},
+			TypeRef:         ref("list<money>"),
+			Addons:          &map[string]interface{}{"tags": map[string]interface{}{"json": "id", "db": "user_id"}},
+		}
+		s, err := g.field(p)
+		assert.Equal(t, err, nil)
+		assert.Equal(t, s, "Id []decimal.Decimal `db:\"user_id\" json:\"id\"`")
+	})
+
+	t.Run("fields", func(t *testing.T) {
+		prop := func(name, typ string, tags map[string]interface{}) core.ModelProperty {
+			p := core.ModelProperty{
+				EntityWithScope: core.EntityWithScope{Entity: core.Entity{Name: name}, Scope: core.EntityScopePublic},
+				TypeRef:         ref(typ),
+			}
+			if tags != nil {
+				p.Addons = &map[string]interface{}{"tags": tags}
+			}
+			return p
+		}
+		s, err := g.fields([]core.ModelProperty{
+			prop("id", "uuid", map[string]interface{}{"json": "id"}),
+			prop("emailAddress", "string", map[string]interface{}{"json": "email"}),
+			prop("age", "int", nil),
+		})
+		assert.Equal(t, err, nil)
+
+		// -> The declarations are left unchanged by gofmt.
+		src := "package p\n\ntype T struct {\n" + s + "\n}\n"
+		b, err := format.Source([]byte(src))
+		assert.Equal(t, err, nil)
+		assert.Equal(t, src, string(b))
+		assert.Equal(t, s, "\tId           uuid.UUID `json:\"id\"`\n\tEmailAddress string    `json:\"email\"`\n\tAge          int")
+
+		s, _ = g.fields(nil)
+		assert.Equal(t, s, "")
+	})
+
+	// -> Pointers and slices of types mapped by import path retain their prefix.
+	t.Run("prefixed import path", func(t *testing.T) {
+		for typ, expected := range map[string]string{
+			"price": "*decimal.Decimal", "blob": "[]storage.Chunk", "node": "yaml.Node",
+		} {
+			s, err := g.field(&core.ModelProperty{
+				EntityWithScope: core.EntityWithScope{Entity: core.Entity{Name: "v"}, Scope: core.EntityScopePublic},
+				TypeRef:         ref(typ),
+			})
+			assert.Equal(t, err, nil)
+			assert.Equal(t, s, "V "+expected)
+		}
+	})
+
+	t.Run("imports", func(t *testing.T) {
+		billing := &core.Package{Entity: core.Entity{Name: "invoice"}, Path: "billing/invoice"}
+		pkg := &core.Package{
+			Models: []core.Model{{
+				Properties: []core.ModelProperty{
+					{TypeRef: ref("map<uuid,datetime>")}, {TypeRef: ref("money")}, {TypeRef: ref("node")},
+				},
+				Methods: []core.Function{{
+					Returns: []*core.ReturnParameter{{TypeRef: ref("duration")}, {TypeRef: ref("gorm.DB")}},
+				}},
+			}},
+			Imports: []*core.Package{billing},
+		}
+		assert.Equal(t, g.imports(pkg, "example.com/app/domain"), `import (
+	"time"
+
+	"example.com/app/domain/billing/invoice"
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)`)
+		assert.Equal(t, g.imports(&core.Package{}, ""), "")
+	})
+}
@@ -0,0 +1,79 @@
+package partials
+
+import (
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/pkg/errors"
+)
+
+// javaPartials returns the template functions of '.java' outputs:
+//
+// • identifier: the name of an entity, as is.
+// • visibility: the access modifier of an entity (e.g. `{{visibility .Scope}}` => 'private'); empty if unset
+// (package-private).
+// • doc: the Javadoc comment of an entity, from its description (e.g. `{{doc .Name .Description}}`).
+// • signature: the signature of a method; a trailing 'error' return parameter is omitted, as errors are thrown (e.g.
+// 'User findById(String id)'). Fails if several return parameters remain.
+// • field: the declaration of a property (e.g. 'String id').
+// • class: the declaration of a model as a class, extending and implementing as per the model (e.g. 'class User
+// extends Base implements Service').
+// • interface: the declaration of the package's interface (e.g. 'interface Service').
+func javaPartials(l lib) map[string]interface{} {
+	j := &javaLib{l}
+	return map[string]interface{}{
+		"identifier": func(name string, _ core.EntityScope) string { return name },
+		"visibility": visibility,
+		"doc":        jsDoc,
+		"signature":  j.signature,
+		"field":      j.field,
+		"class":      j.class,
+		"interface":  func(i *core.Interface) string { return "interface " + interfaceName(i) },
+	}
+}
+
+type javaLib struct {
+	lib
+}
+
+func (j *javaLib) signature(fn *core.Function) (string, error) {
+	params := ""
+	for i, p := range fn.Params {
+		t, err := j.mapType(p.TypeRef)
+		if err != nil {
+			return "", err
+		}
+		if i != 0 {
+			params += ", "
+		}
+		params += t + " " + paramName(p, i)
+	}
+	returns, err := j.mapTypes(thrownReturns(fn.Returns))
+	if err != nil {
+		return "", err
+	}
+
+	r := "void"
+	switch len(returns) {
+	case 0:
+	case 1:
+		r = returns[0]
+	default:
+		return "", errors.Errorf("signature: method '%s' has %d return parameters; Java supports one", fn.Name, len(returns))
+	}
+	return r + " " + fn.Name + "(" + params + ")", nil
+}
+
+func (j *javaLib) field(p *core.ModelProperty) (string, error) {
+	t, err := j.mapType(p.TypeRef)
+	if err != nil {
+		return "", err
+	}
+	return t + " " + p.Name, nil
+}
+
+func (j *javaLib) class(m *core.Model) (string, error) {
+	h, err := j.heritage(m)
+	if err != nil {
+		return "", err
+	}
+	return "class " + m.Name + h, nil
+}
@@ -1,12 +1,154 @@
 package partials
 
-var goPartials = map[string]interface{}{}
+import (
+	"fmt"
+	"strings"
 
-func GetByExtension(ext string) map[string]interface{} {
-	switch ext {
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/pkg/errors"
+)
+
+// GetByExtension returns the template functions of the given extension, along with the functions shared by all
+// extensions (e.g. 'mapType'); `types` represents the custom types declared by the configuration.
+func GetByExtension(ext string, types map[string]map[string]string) map[string]interface{} {
+	fm := map[string]interface{}{
+		"mapType": func(t any) (string, error) { return MapType(ext, types, t) },
+	}
+
+	for k, v := range byExtension(lib{ext: ext, types: types}) {
+		fm[k] = v
+	}
+	return fm
+}
+
+// Unavailable returns stubs of the functions defined for other extensions than the given one, failing upon
+// execution; templates shared by several extensions (e.g. the templates library) may then be parsed regardless of the
+// extension they are executed for.
+func Unavailable(ext string) map[string]interface{} {
+	own := byExtension(lib{ext: ext})
+	stubs := make(map[string]interface{})
+	for _, other := range []string{"go", "ts", "java", "py"} {
+		for name := range byExtension(lib{ext: other}) {
+			if _, ok := own[name]; ok {
+				continue
+			}
+			name := name
+			stubs[name] = func(...any) (string, error) {
+				return "", errors.Errorf("%s: function is not available for '.%s' outputs", name, ext)
+			}
+		}
+	}
+	return stubs
+}
+
+func byExtension(l lib) map[string]interface{} {
+	canonical := l.ext
+	if alias, ok := extAliases[l.ext]; ok {
+		canonical = alias // e.g. 'tsx' => 'ts'.
+	}
+	switch canonical {
 	case "go":
-		return goPartials
+		return goPartials(l)
+	case "ts":
+		return tsPartials(l)
+	case "java":
+		return javaPartials(l)
+	case "py":
+		return pyPartials(l)
 	default:
 		return nil
 	}
 }
+
+// lib represents the base of the partials libraries.
+type lib struct {
+	ext string
+	// Represents the custom types declared by the configuration (see `core.Config.Types`).
+	types map[string]map[string]string
+}
+
+func (l *lib) mapType(t *core.TypeRef) (string, error) {
+	return MapType(l.ext, l.types, t)
+}
+
+// mapTypes maps the types of the given parameters.
+func (l *lib) mapTypes(ps []*core.FnParameter) ([]string, error) {
+	ts := make([]string, 0, len(ps))
+	for _, p := range ps {
+		t, err := l.mapType(p.TypeRef)
+		if err != nil {
+			return nil, err
+		}
+		ts = append(ts, t)
+	}
+	return ts, nil
+}
+
+// heritage returns the 'extends' and 'implements' clauses of a class declaring the given model (e.g. ' extends Base
+// implements Service'); empty if none.
+func (l *lib) heritage(m *core.Model) (string, error) {
+	s := ""
+	for _, h := range []struct {
+		keyword string
+		ref     *core.TypeRef
+	}{{"extends", m.ExtendsRef}, {"implements", m.ImplementsRef}} {
+		if h.ref == nil {
+			continue
+		}
+		t, err := l.mapType(h.ref)
+		if err != nil {
+			return "", err
+		}
+		s += " " + h.keyword + " " + t
+	}
+	return s, nil
+}
+
+// paramName returns the name of the i-th parameter; unnamed parameters are named after their position (e.g. 'p2').
+func paramName(p *core.FnParameter, i int) string {
+	if p.Name == "" {
+		return fmt.Sprintf("p%d", i+1)
+	}
+	return p.Name
+}
+
+// thrownReturns returns the return parameters of languages throwing their errors (e.g. TypeScript), omitting the
+// trailing 'error' return parameter, if any (e.g. '(User, error)' => 'User').
+func thrownReturns(ps []*core.ReturnParameter) []*core.ReturnParameter {
+	if n := len(ps); n != 0 && ps[n-1].TypeRef != nil && ps[n-1].TypeRef.IsBuiltin() && ps[n-1].TypeRef.Name == "error" {
+		return ps[:n-1]
+	}
+	return ps
+}
+
+// visibility returns the access modifier of the given scope; empty if unset.
+func visibility(scope core.EntityScope) string {
+	if scope.IsValid() {
+		return string(scope)
+	}
+	return ""
+}
+
+// jsDoc returns the Javadoc/JSDoc comment of an entity; empty if it has no description.
+func jsDoc(name, description string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return ""
+	}
+	lines := strings.Split(name+" "+description, "\n")
+	if len(lines) == 1 {
+		return "/** " + lines[0] + " */"
+	}
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(" * "+strings.TrimSpace(l), " ")
+	}
+	return "/**\n" + strings.Join(lines, "\n") + "\n */"
+}
+
+// interfaceName returns the name of the package's interface; 'Service' if unnamed.
+func interfaceName(i *core.Interface) string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return "Service"
+}
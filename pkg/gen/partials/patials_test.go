@@ -0,0 +1,97 @@
+package partials
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+func TestGetByExtension(t *testing.T) {
+	ref := func(s string) *core.TypeRef {
+		r, err := core.ParseType(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	entity := func(name string, scope core.EntityScope) core.EntityWithScope {
+		return core.EntityWithScope{Entity: core.Entity{Name: name}, Scope: scope}
+	}
+
+	service := &core.Interface{Name: "Service"}
+	model := &core.Model{
+		EntityWithScope: entity("User", core.EntityScopePublic),
+		ExtendsRef:      &core.TypeRef{Name: "Base", Model: &core.Model{}},
+		ImplementsRef:   &core.TypeRef{Name: "Service", Interface: service},
+	}
+	prop := &core.ModelProperty{EntityWithScope: entity("createdAt", core.EntityScopePrivate), TypeRef: ref("datetime?")}
+	fn := &core.Function{
+		EntityWithScope: entity("findById", core.EntityScopePublic),
+		Params:          []*core.FnParameter{{Name: "userId", TypeRef: ref("uuid")}},
+		Returns:         []*core.ReturnParameter{{TypeRef: ref("User")}, {TypeRef: ref("error")}},
+	}
+	multi := &core.Function{
+		EntityWithScope: entity("split", ""),
+		Returns:         []*core.ReturnParameter{{TypeRef: ref("int")}, {TypeRef: ref("string")}},
+	}
+
+	tests := []struct {
+		ext      string
+		fn       string
+		arg      any
+		expected string
+	}{
+		{"ts", "signature", fn, "findById(userId: string): User"},
+		{"ts", "signature", multi, "split(): [number, string]"},
+		{"ts", "field", prop, "createdAt: Date | null"},
+		{"ts", "class", model, "class User extends Base implements Service"},
+		{"tsx", "interface", service, "interface Service"},
+		{"java", "signature", fn, "User findById(java.util.UUID userId)"},
+		{"java", "field", prop, "java.time.Instant createdAt"},
+		{"java", "class", model, "class User extends Base implements Service"},
+		{"java", "interface", &core.Interface{}, "interface Service"},
+		{"py", "signature", fn, "find_by_id(self, user_id: UUID) -> User"},
+		{"py", "signature", multi, "split(self) -> tuple[int, str]"},
+		{"py", "field", prop, "__created_at: datetime | None"},
+		{"py", "class", model, "class User(Base, Service)"},
+		{"py", "interface", service, "class Service(Protocol)"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ext+":"+test.fn, func(t *testing.T) {
+			var (
+				actual string
+				err    error
+			)
+			switch f := GetByExtension(test.ext, nil)[test.fn].(type) {
+			case func(*core.Function) (string, error):
+				actual, err = f(test.arg.(*core.Function))
+			case func(*core.ModelProperty) (string, error):
+				actual, err = f(test.arg.(*core.ModelProperty))
+			case func(*core.Model) (string, error):
+				actual, err = f(test.arg.(*core.Model))
+			case func(*core.Interface) string:
+				actual = f(test.arg.(*core.Interface))
+			default:
+				t.Fatalf("unexpected function of type %T", f)
+			}
+			assert.Equal(t, err, nil)
+			assert.Equal(t, actual, test.expected)
+		})
+	}
+
+	t.Run("java: several return parameters", func(t *testing.T) {
+		_, err := GetByExtension("java", nil)["signature"].(func(*core.Function) (string, error))(multi)
+		assert.NotEqual(t, err, nil)
+	})
+
+	t.Run("doc", func(t *testing.T) {
+		doc := func(ext string) string {
+			return GetByExtension(ext, nil)["doc"].(func(string, string) string)("User", "represents a user.")
+		}
+		assert.Equal(t, doc("ts"), "/** User represents a user. */")
+		assert.Equal(t, doc("py"), `"""Represents a user."""`)
+		assert.Equal(t, jsDoc("User", "represents a user.\nSee Account."), "/**\n * User represents a user.\n * See Account.\n */")
+	})
+}
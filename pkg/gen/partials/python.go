@@ -0,0 +1,113 @@
+package partials
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/iancoleman/strcase"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+// pyPartials returns the template functions of '.py' outputs:
+//
+// • identifier: the name of an entity in snake case, prefixed as per its scope: '_' if protected, '__' if private
+// (e.g. `{{identifier .Name .Scope}}` => '_created_at').
+// • doc: the docstring of an entity, from its description (e.g. `{{doc .Name .Description}}` => '"""Represents a
+// user."""').
+// • signature: the signature of a method, without the 'def' keyword; a trailing 'error' return parameter is omitted,
+// as errors are raised, and several return parameters are returned as a tuple (e.g. 'find_by_id(self, id: str) ->
+// User').
+// • field: the declaration of a property (e.g. 'created_at: datetime').
+// • class: the declaration of a model as a class, inheriting as per the model (e.g. 'class User(Base, Service)').
+// • interface: the declaration of the package's interface, as a protocol (e.g. 'class Service(Protocol)').
+func pyPartials(l lib) map[string]interface{} {
+	py := &pyLib{l}
+	return map[string]interface{}{
+		"identifier": pyIdentifier,
+		"doc":        pyDoc,
+		"signature":  py.signature,
+		"field":      py.field,
+		"class":      py.class,
+		"interface":  func(i *core.Interface) string { return "class " + interfaceName(i) + "(Protocol)" },
+	}
+}
+
+type pyLib struct {
+	lib
+}
+
+// pyIdentifier returns `name` in snake case, prefixed as per the given scope.
+func pyIdentifier(name string, scope core.EntityScope) string {
+	name = strcase.ToSnake(name)
+	switch scope {
+	case core.EntityScopePrivate:
+		return "__" + name
+	case core.EntityScopeProtected:
+		return "_" + name
+	default:
+		return name
+	}
+}
+
+// pyDoc returns the docstring of an entity; empty if it has no description.
+func pyDoc(_, description string) string {
+	description = strings.TrimSpace(description)
+	if description == "" {
+		return ""
+	}
+	r := []rune(description)
+	r[0] = unicode.ToUpper(r[0])
+	return `"""` + string(r) + `"""`
+}
+
+func (py *pyLib) signature(fn *core.Function) (string, error) {
+	params := []string{"self"}
+	for i, p := range fn.Params {
+		t, err := py.mapType(p.TypeRef)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, pyIdentifier(paramName(p, i), "")+": "+t)
+	}
+	returns, err := py.mapTypes(thrownReturns(fn.Returns))
+	if err != nil {
+		return "", err
+	}
+
+	var r string
+	switch len(returns) {
+	case 0:
+		r = "None"
+	case 1:
+		r = returns[0]
+	default:
+		r = "tuple[" + strings.Join(returns, ", ") + "]"
+	}
+	return pyIdentifier(fn.Name, fn.Scope) + "(" + strings.Join(params, ", ") + ") -> " + r, nil
+}
+
+func (py *pyLib) field(p *core.ModelProperty) (string, error) {
+	t, err := py.mapType(p.TypeRef)
+	if err != nil {
+		return "", err
+	}
+	return pyIdentifier(p.Name, p.Scope) + ": " + t, nil
+}
+
+func (py *pyLib) class(m *core.Model) (string, error) {
+	bases := make([]string, 0, 2)
+	for _, ref := range []*core.TypeRef{m.ExtendsRef, m.ImplementsRef} {
+		if ref == nil {
+			continue
+		}
+		t, err := py.mapType(ref)
+		if err != nil {
+			return "", err
+		}
+		bases = append(bases, t)
+	}
+	if len(bases) == 0 {
+		return "class " + m.Name, nil
+	}
+	return "class " + m.Name + "(" + strings.Join(bases, ", ") + ")", nil
+}
@@ -0,0 +1,77 @@
+package partials
+
+import (
+	"strings"
+
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+// tsPartials returns the template functions of '.ts' outputs:
+//
+// • identifier: the name of an entity, as is.
+// • visibility: the access modifier of an entity (e.g. `{{visibility .Scope}}` => 'private'); empty if unset.
+// • doc: the JSDoc comment of an entity, from its description (e.g. `{{doc .Name .Description}}`).
+// • signature: the signature of a method; a trailing 'error' return parameter is omitted, as errors are thrown, and
+// several return parameters are returned as a tuple (e.g. 'findById(id: string): User').
+// • field: the declaration of a property (e.g. 'id: string').
+// • class: the declaration of a model as a class, extending and implementing as per the model (e.g. 'class User
+// extends Base implements Service').
+// • interface: the declaration of the package's interface (e.g. 'interface Service').
+func tsPartials(l lib) map[string]interface{} {
+	ts := &tsLib{l}
+	return map[string]interface{}{
+		"identifier": func(name string, _ core.EntityScope) string { return name },
+		"visibility": visibility,
+		"doc":        jsDoc,
+		"signature":  ts.signature,
+		"field":      ts.field,
+		"class":      ts.class,
+		"interface":  func(i *core.Interface) string { return "interface " + interfaceName(i) },
+	}
+}
+
+type tsLib struct {
+	lib
+}
+
+func (ts *tsLib) signature(fn *core.Function) (string, error) {
+	params := make([]string, 0, len(fn.Params))
+	for i, p := range fn.Params {
+		t, err := ts.mapType(p.TypeRef)
+		if err != nil {
+			return "", err
+		}
+		params = append(params, paramName(p, i)+": "+t)
+	}
+	returns, err := ts.mapTypes(thrownReturns(fn.Returns))
+	if err != nil {
+		return "", err
+	}
+
+	var r string
+	switch len(returns) {
+	case 0:
+		r = "void"
+	case 1:
+		r = returns[0]
+	default:
+		r = "[" + strings.Join(returns, ", ") + "]"
+	}
+	return fn.Name + "(" + strings.Join(params, ", ") + "): " + r, nil
+}
+
+func (ts *tsLib) field(p *core.ModelProperty) (string, error) {
+	t, err := ts.mapType(p.TypeRef)
+	if err != nil {
+		return "", err
+	}
+	return p.Name + ": " + t, nil
+}
+
+func (ts *tsLib) class(m *core.Model) (string, error) {
+	h, err := ts.heritage(m)
+	if err != nil {
+		return "", err
+	}
+	return "class " + m.Name + h, nil
+}
@@ -0,0 +1,270 @@
+package partials
+
+import (
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/pkg/errors"
+)
+
+// TypeMap represents the mapping of the generic types (see `core.BuiltinTypes`) to the types of a language.
+type TypeMap struct {
+	// Types maps the scalar types (e.g. 'uuid' => 'string').
+	Types map[string]string
+	// Generics maps the generic types to a format, whose verbs are replaced by the type arguments (e.g. 'list' =>
+	// '[]%s').
+	Generics map[string]string
+	// Optional represents the format of optional types (e.g. '*%s').
+	Optional string
+	// Nullable represents the types whose zero value is already nil; optionals of such types are left as is (e.g. Go's
+	// 'list<string>?' => '[]string').
+	Nullable map[string]bool
+	// Boxed maps the types that cannot be used as type arguments or optionals to their boxed counterpart (e.g. Java's
+	// 'int' => 'Integer').
+	Boxed map[string]string
+	// Qualified is set if models of other packages, and custom types mapped by import path, are referred to by their
+	// package name (e.g. Go's 'invoice.Invoice').
+	Qualified bool
+}
+
+// typeMaps represents the built-in type maps, keyed by file extension.
+var typeMaps = map[string]*TypeMap{
+	"go": {
+		Types: map[string]string{
+			"any": "any", "bool": "bool", "string": "string", "bytes": "[]byte", "byte": "byte", "rune": "rune",
+			"error": "error", "int": "int", "int8": "int8", "int16": "int16", "int32": "int32", "int64": "int64",
+			"uint": "uint", "uint8": "uint8", "uint16": "uint16", "uint32": "uint32", "uint64": "uint64",
+			"float32": "float32", "float64": "float64", "decimal": "float64",
+			"uuid": "string", "date": "time.Time", "datetime": "time.Time", "duration": "time.Duration",
+		},
+		Generics:  map[string]string{"list": "[]%s", "set": "map[%s]struct{}", "map": "map[%s]%s"},
+		Optional:  "*%s",
+		Nullable:  map[string]bool{"any": true, "bytes": true, "error": true, "list": true, "set": true, "map": true},
+		Qualified: true,
+	},
+	"ts": {
+		Types: map[string]string{
+			"any": "unknown", "bool": "boolean", "string": "string", "bytes": "Uint8Array", "byte": "number",
+			"rune": "string", "error": "Error", "int": "number", "int8": "number", "int16": "number", "int32": "number",
+			"int64": "number", "uint": "number", "uint8": "number", "uint16": "number", "uint32": "number",
+			"uint64": "number", "float32": "number", "float64": "number", "decimal": "string",
+			"uuid": "string", "date": "string", "datetime": "Date", "duration": "number",
+		},
+		Generics: map[string]string{"list": "Array<%s>", "set": "Set<%s>", "map": "Record<%s, %s>"},
+		Optional: "%s | null",
+	},
+	"java": {
+		Types: map[string]string{
+			"any": "Object", "bool": "boolean", "string": "String", "bytes": "byte[]", "byte": "byte", "rune": "char",
+			"error": "Exception", "int": "int", "int8": "byte", "int16": "short", "int32": "int", "int64": "long",
+			"uint": "long", "uint8": "short", "uint16": "int", "uint32": "long", "uint64": "long",
+			"float32": "float", "float64": "double", "decimal": "java.math.BigDecimal",
+			"uuid": "java.util.UUID", "date": "java.time.LocalDate", "datetime": "java.time.Instant",
+			"duration": "java.time.Duration",
+		},
+		Generics: map[string]string{
+			"list": "java.util.List<%s>", "set": "java.util.Set<%s>", "map": "java.util.Map<%s, %s>",
+		},
+		Optional: "%s",
+		Boxed: map[string]string{
+			"boolean": "Boolean", "byte": "Byte", "char": "Character", "short": "Short", "int": "Integer",
+			"long": "Long", "float": "Float", "double": "Double",
+		},
+	},
+	"kt": {
+		Types: map[string]string{
+			"any": "Any", "bool": "Boolean", "string": "String", "bytes": "ByteArray", "byte": "Byte", "rune": "Char",
+			"error": "Throwable", "int": "Int", "int8": "Byte", "int16": "Short", "int32": "Int", "int64": "Long",
+			"uint": "UInt", "uint8": "UByte", "uint16": "UShort", "uint32": "UInt", "uint64": "ULong",
+			"float32": "Float", "float64": "Double", "decimal": "java.math.BigDecimal",
+			"uuid": "java.util.UUID", "date": "java.time.LocalDate", "datetime": "java.time.Instant",
+			"duration": "java.time.Duration",
+		},
+		Generics: map[string]string{"list": "List<%s>", "set": "Set<%s>", "map": "Map<%s, %s>"},
+		Optional: "%s?",
+	},
+	"py": {
+		Types: map[string]string{
+			"any": "Any", "bool": "bool", "string": "str", "bytes": "bytes", "byte": "int", "rune": "str",
+			"error": "Exception", "int": "int", "int8": "int", "int16": "int", "int32": "int", "int64": "int",
+			"uint": "int", "uint8": "int", "uint16": "int", "uint32": "int", "uint64": "int",
+			"float32": "float", "float64": "float", "decimal": "Decimal",
+			"uuid": "UUID", "date": "date", "datetime": "datetime", "duration": "timedelta",
+		},
+		Generics:  map[string]string{"list": "list[%s]", "set": "set[%s]", "map": "dict[%s, %s]"},
+		Optional:  "%s | None",
+		Qualified: true,
+	},
+	"rs": {
+		Types: map[string]string{
+			"any": "Box<dyn std::any::Any>", "bool": "bool", "string": "String", "bytes": "Vec<u8>", "byte": "u8",
+			"rune": "char", "error": "Box<dyn std::error::Error>", "int": "i64", "int8": "i8", "int16": "i16",
+			"int32": "i32", "int64": "i64", "uint": "u64", "uint8": "u8", "uint16": "u16", "uint32": "u32",
+			"uint64": "u64", "float32": "f32", "float64": "f64", "decimal": "Decimal",
+			"uuid": "Uuid", "date": "NaiveDate", "datetime": "DateTime<Utc>", "duration": "std::time::Duration",
+		},
+		Generics: map[string]string{
+			"list": "Vec<%s>", "set": "std::collections::HashSet<%s>", "map": "std::collections::HashMap<%s, %s>",
+		},
+		Optional: "Option<%s>",
+	},
+}
+
+// extAliases maps the extensions sharing the type map of another one.
+var extAliases = map[string]string{"tsx": "ts", "mts": "ts", "kts": "kt", "pyi": "py"}
+
+// GetTypeMap returns the built-in type map of the given extension; nil if none.
+func GetTypeMap(ext string) *TypeMap {
+	if alias, ok := extAliases[ext]; ok {
+		ext = alias
+	}
+	return typeMaps[ext]
+}
+
+// typeMapper maps generic types to the types of a language.
+type typeMapper struct {
+	ext string
+	tm  *TypeMap
+	// Represents the custom types (see `core.Config.Types`); they take precedence over the type map.
+	custom map[string]map[string]string
+}
+
+// MapType maps `t` to its counterpart in the language designated by `ext`, as per its built-in type map, overridden
+// by `custom` (see `core.Config.Types`).
+//
+// `t` is either a resolved type (see `core.TypeRef`), or a type expression (e.g. 'list<uuid>').
+func MapType(ext string, custom map[string]map[string]string, t any) (string, error) {
+	var (
+		ref *core.TypeRef
+		err error
+	)
+	switch t := t.(type) {
+	case *core.TypeRef:
+		ref = t
+	case string:
+		if ref, err = core.ParseType(t); err != nil {
+			return "", err
+		}
+	default:
+		return "", errors.Errorf("mapType: unexpected argument of type %T", t)
+	}
+	if ref == nil {
+		return "", errors.New("mapType: unresolved type")
+	}
+
+	m := &typeMapper{ext: ext, tm: GetTypeMap(ext), custom: custom}
+	s, err := m.mapType(ref, false)
+	if err != nil {
+		return "", errors.Wrapf(err, "mapType: failed to map type '%s' to extension '%s'", ref, ext)
+	}
+	return s, nil
+}
+
+func (m *typeMapper) mapType(t *core.TypeRef, boxed bool) (string, error) {
+	s, nullable, err := m.name(t, boxed || t.Optional)
+	if err != nil {
+		return "", err
+	}
+	if t.Optional && !nullable {
+		s = fmt.Sprintf(m.tm.Optional, s)
+	}
+	return s, nil
+}
+
+// name returns the name of `t`, disregarding whether it is optional; `nullable` is set if optionals of `t` are left
+// as is.
+func (m *typeMapper) name(t *core.TypeRef, boxed bool) (s string, nullable bool, err error) {
+	// [1] Custom types; unqualified only.
+	if c, ok := m.custom[t.Name]; ok && t.Qualifier == "" {
+		if s, ok = c[m.ext]; !ok {
+			s, ok = c[extAliases[m.ext]] // e.g. 'tsx' => 'ts'.
+		}
+		if ok {
+			if m.tm != nil && m.tm.Qualified {
+				s = importPathName(s)
+			}
+			return s, false, nil
+		}
+		if _, builtin := core.BuiltinTypes[t.Name]; !builtin {
+			return t.Name, false, nil
+		}
+	}
+	if m.tm == nil {
+		return "", false, errors.New("no type map is defined for the extension")
+	}
+
+	// [2] Models, interfaces, and external types.
+	switch {
+	case t.Model != nil, t.Interface != nil:
+		if m.tm.Qualified && t.Qualifier != "" {
+			return t.Package.Name + "." + t.Name, false, nil
+		}
+		return t.Name, false, nil
+	case t.Qualifier != "":
+		return t.Qualifier + "." + t.Name, false, nil
+	}
+
+	// [3] Built-in types.
+	if f, ok := m.tm.Generics[t.Name]; ok {
+		args := make([]any, 0, len(t.Args))
+		for _, a := range t.Args {
+			s, err := m.mapType(a, true)
+			if err != nil {
+				return "", false, err
+			}
+			args = append(args, s)
+		}
+		if n := strings.Count(f, "%s"); n != len(args) {
+			return "", false, errors.Errorf("type '%s' expects %d type arguments, got %d", t.Name, n, len(args))
+		}
+		return fmt.Sprintf(f, args...), m.tm.Nullable[t.Name], nil
+	}
+	if s, ok := m.tm.Types[t.Name]; ok {
+		if b, ok := m.tm.Boxed[s]; ok && boxed {
+			s = b
+		}
+		return s, m.tm.Nullable[t.Name], nil
+	}
+	// -> Unresolved models (e.g. parsed from a type expression).
+	return t.Name, false, nil
+}
+
+// importPathName returns the name of a type mapped by import path as referred to by source code, prefix included
+// (e.g. 'github.com/google/uuid.UUID' => 'uuid.UUID', '*gopkg.in/yaml.v3.Node' => '*yaml.Node'); `s` is returned as
+// is if not mapped by import path.
+func importPathName(s string) string {
+	i, j := strings.LastIndex(s, "/"), strings.LastIndex(s, ".")
+	if i == -1 || j < i {
+		return s
+	}
+	prefix := s[:len(s)-len(strings.TrimLeft(s, "*[]"))]
+	return prefix + packageName(s[len(prefix):j]) + "." + s[j+1:]
+}
+
+// packageName returns the name a package is conventionally referred to by: the last element of its import path,
+// without its major version (e.g. 'gopkg.in/yaml.v3' => 'yaml', 'github.com/jackc/pgx/v5' => 'pgx').
+func packageName(importPath string) string {
+	elem := path.Base(importPath)
+	if isMajorVersion(elem) && path.Dir(importPath) != "." {
+		elem = path.Base(path.Dir(importPath))
+	}
+	if i := strings.LastIndex(elem, "."); i > 0 && isMajorVersion(elem[i+1:]) {
+		elem = elem[:i]
+	}
+	return elem
+}
+
+// isMajorVersion returns true if `s` denotes a major version (e.g. 'v3').
+func isMajorVersion(s string) bool {
+	if len(s) < 2 || s[0] != 'v' {
+		return false
+	}
+	for _, r := range s[1:] {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
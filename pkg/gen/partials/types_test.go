@@ -0,0 +1,72 @@
+package partials
+
+import (
+	"testing"
+
+	"github.com/go-playground/assert"
+	"github.com/maxzaleski/codegen/internal/core"
+)
+
+func TestMapType(t *testing.T) {
+	custom := map[string]map[string]string{
+		"money": {"go": "decimal.Decimal"},
+		"uuid":  {"go": "uuid.UUID"},
+		"node":  {"go": "gopkg.in/yaml.v3.Node"},
+		"conn":  {"go": "*github.com/jackc/pgx/v5.Conn"},
+	}
+
+	tests := []struct {
+		ext      string
+		input    string
+		expected string
+	}{
+		{"go", "string", "string"},
+		{"go", "int?", "*int"},
+		{"go", "list<uuid>?", "[]uuid.UUID"},
+		{"go", "map<string,list<datetime?>>", "map[string][]*time.Time"},
+		{"go", "money?", "*decimal.Decimal"},
+		{"go", "node?", "*yaml.Node"},
+		{"go", "list<conn>", "[]*pgx.Conn"},
+		{"go", "\\*gorm.DB", "*gorm.DB"},
+		{"ts", "list<int?>", "Array<number | null>"},
+		{"tsx", "map<string,bool>", "Record<string, boolean>"},
+		{"ts", "uuid", "string"},
+		{"ts", "money", "money"},
+		{"java", "int", "int"},
+		{"java", "int?", "Integer"},
+		{"java", "map<string,float64>", "java.util.Map<String, Double>"},
+		{"kt", "list<int64>?", "List<Long>?"},
+		{"py", "set<string>?", "set[str] | None"},
+		{"rs", "list<uint8>?", "Option<Vec<u8>>"},
+		{"rs", "User", "User"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ext+":"+test.input, func(t *testing.T) {
+			actual, err := MapType(test.ext, custom, test.input)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, actual, test.expected)
+		})
+	}
+
+	t.Run("resolved model", func(t *testing.T) {
+		pkg := &core.Package{Entity: core.Entity{Name: "invoice"}, Path: "billing/invoice"}
+		ref := &core.TypeRef{
+			Name: "list", Args: []*core.TypeRef{
+				{Name: "Invoice", Qualifier: "billing/invoice", Model: &core.Model{}, Package: pkg},
+			},
+		}
+		for ext, expected := range map[string]string{"go": "[]invoice.Invoice", "ts": "Array<Invoice>"} {
+			actual, err := MapType(ext, nil, ref)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, actual, expected)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, input := range []any{"list<string", 42, "string"} {
+			_, err := MapType("tmpl", nil, input)
+			assert.NotEqual(t, err, nil)
+		}
+	})
+}
@@ -3,18 +3,14 @@ package gen
 import (
 	"fmt"
 	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/lib/diff"
 	"github.com/maxzaleski/codegen/internal/slog"
+	"github.com/maxzaleski/codegen/pkg/vfs"
 	"github.com/pkg/errors"
 	"os"
+	"strings"
 )
 
-const (
-	fileOutcomeSuccess jobOutcome = "created"
-	fileOutcomeIgnored jobOutcome = "already-exists"
-)
-
-type jobOutcome string
-
 func removeTmpDir(md *core.Metadata, l slog.ILogger) error {
 	path := md.Cwd + "/tmp"
 
@@ -26,6 +22,20 @@ func removeTmpDir(md *core.Metadata, l slog.ILogger) error {
 	return nil
 }
 
+// diffFile returns the unified diff between the file at `path` and the given bytes; file names are relative to `cwd`.
+func diffFile(fsys vfs.FS, cwd, path string, b []byte) (string, error) {
+	old, err := fsys.ReadFile(path)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read file at '%s'", path)
+	}
+	return unifiedDiff(cwd, path, old, b), nil
+}
+
+func unifiedDiff(cwd, path string, old, b []byte) string {
+	rel := strings.TrimPrefix(strings.Replace(path, cwd, "", 1), "/")
+	return diff.Unified("a/"+rel, "b/"+rel, old, b, diff.DefaultContext)
+}
+
 func printWorkerMetrics(logger slog.ILogger, m map[int]int, wc int) {
 	keys, total, highest := 0, 0, 0
 	for _, w := range m {
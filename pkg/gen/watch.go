@@ -0,0 +1,222 @@
+package gen
+
+import (
+	"context"
+	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/internal/slog"
+	"github.com/maxzaleski/codegen/pkg/gen/modules"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+type (
+	// WatchConfig represents the configuration of the watch mode.
+	WatchConfig struct {
+		// Interval at which the watched files are polled.
+		Interval time.Duration
+		// Debounce is the period without change to observe before re-executing the affected jobs.
+		Debounce time.Duration
+	}
+
+	// watcher polls the files relevant to the generation; polling is used as to not require any system dependency.
+	watcher struct {
+		c      Config
+		began  time.Time
+		logger slog.INamedLogger
+
+		// Represents the last modified time of the watched files (values in unix).
+		filesMap map[string]int64
+		// Represents the last modified time of the packages, as per the last parsed specification.
+		pkgsMap map[string]int64
+		// Represents the template names referenced by the configuration, keyed by file path.
+		templatesMap map[string]string
+		configPath   string
+	}
+)
+
+// Watch executes the code generation, then re-executes the affected jobs whenever '.codegen/config.yaml',
+// '.codegen/pkg/**', '.codegen/templates/**' or a template referenced by the configuration changes; changes to
+// templates of the library that are not referenced by the configuration (e.g. partials) affect all jobs.
+//
+// `onRun` is called upon each execution. Watch blocks until the context is cancelled.
+func Watch(ctx context.Context, c Config, wc WatchConfig, onRun func(res *Result, err error)) error {
+	began := time.Now()
+	if c.TemplateCache == nil {
+		c.TemplateCache = modules.NewTemplateCache() // -> Templates are only recompiled upon change.
+	}
+	w := &watcher{
+		c:      c,
+		began:  began,
+		logger: slog.NewNamed(c.logger(began), "watch", slog.LightBlue),
+	}
+
+	c.Filter = nil
+	onRun(Execute(c, time.Now()))
+	w.refresh()
+
+	ticker := time.NewTicker(wc.Interval)
+	defer ticker.Stop()
+
+	var (
+		changed    []string
+		lastChange time.Time
+	)
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			if cs := w.poll(); len(cs) != 0 {
+				w.logger.Log("change", "msg", "detected file changes", "count", len(cs))
+				changed, lastChange = append(changed, cs...), now
+				continue
+			}
+			// -> Debounce: wait for changes to settle.
+			if len(changed) == 0 || now.Sub(lastChange) < wc.Debounce {
+				continue
+			}
+
+			c.Filter, changed = w.filter(changed), nil
+			if f := c.Filter; f != nil && len(f.Pkgs) == 0 && len(f.Templates) == 0 {
+				w.logger.Log("filter", "msg", "no job affected")
+				w.refresh()
+				continue
+			}
+			onRun(Execute(c, time.Now()))
+			w.refresh()
+		}
+	}
+}
+
+// refresh parses the specification, and records the state of the watched files.
+func (w *watcher) refresh() {
+	w.templatesMap = make(map[string]string)
+	w.configPath = ""
+
+	spec, err := core.NewSpec(slog.New(false, w.began), w.c.Location)
+	if spec != nil {
+		w.pkgsMap = spec.Metadata.PkgsLastModifiedMap
+	}
+	if err == nil {
+		w.configPath = spec.Metadata.CodegenDir + "/config.yaml"
+		for _, s := range spec.Config.Scopes() {
+			for _, j := range s.Jobs {
+				for _, t := range j.Templates {
+					w.templatesMap[spec.Metadata.TemplatePath(t.Name)] = t.Name
+				}
+			}
+		}
+	}
+	w.filesMap = w.stat()
+}
+
+// poll returns the paths of the files that were created, modified or deleted since the last poll.
+func (w *watcher) poll() []string {
+	fm, changed := w.stat(), make([]string, 0)
+	for path, mt := range fm {
+		if w.filesMap[path] != mt {
+			changed = append(changed, path)
+		}
+	}
+	for path := range w.filesMap {
+		if _, ok := fm[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	w.filesMap = fm
+	return changed
+}
+
+func (w *watcher) stat() map[string]int64 {
+	fm := make(map[string]int64)
+	record := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			fm[path] = info.ModTime().UnixNano()
+		}
+	}
+
+	// The configuration directory is located as per `core.NewSpec`.
+	cwd, err := os.Getwd()
+	if err != nil {
+		return fm
+	}
+	if w.c.Location != "" {
+		cwd += "/" + w.c.Location
+	}
+	cdp := cwd + "/" + core.DomainDir
+
+	record(cdp + "/config.yaml")
+	for _, dir := range []string{cdp + "/pkg", w.templatesDir()} {
+		_ = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				fm[path] = info.ModTime().UnixNano()
+			}
+			return nil
+		})
+	}
+	for path := range w.templatesMap {
+		record(path)
+	}
+	return fm
+}
+
+// filter returns the filter retaining the jobs affected by the given changes; nil if all jobs are affected. Jobs of
+// other packages depending on a changed one (see `override-on`) are retained by `JobFilter.matches`.
+func (w *watcher) filter(changed []string) *JobFilter {
+	f, configChanged, libChanged := &JobFilter{}, false, false
+	for _, path := range changed {
+		if name, ok := w.templatesMap[path]; ok {
+			f.Templates = append(f.Templates, name)
+		} else if path == w.configPath || w.configPath == "" {
+			configChanged = true
+		} else if strings.HasPrefix(path, w.templatesDir()+string(filepath.Separator)) {
+			libChanged = true
+		}
+	}
+	switch {
+	case configChanged:
+		w.logger.Log("filter", "msg", "configuration changed, executing all jobs")
+		return nil
+	case libChanged:
+		w.logger.Log("filter", "msg", "templates library changed, executing all jobs")
+		return nil
+	}
+
+	// -> Compare the last modified time of the packages against the previous specification.
+	spec, err := core.NewSpec(slog.New(false, w.began), w.c.Location)
+	if err != nil {
+		return nil // Let the execution surface the error.
+	}
+	nm := spec.Metadata.PkgsLastModifiedMap
+	for pkg, mt := range nm {
+		if w.pkgsMap[pkg] != mt {
+			f.Pkgs = append(f.Pkgs, pkg)
+		}
+	}
+	// -> Deleted packages only affect unique jobs.
+	for pkg := range w.pkgsMap {
+		if _, ok := nm[pkg]; !ok {
+			f.Pkgs = append(f.Pkgs, pkg)
+		}
+	}
+	sort.Strings(f.Pkgs)
+
+	w.logger.Log("filter", "msg", "executing affected jobs", "packages", f.Pkgs, "templates", f.Templates)
+	return f
+}
+
+// templatesDir returns the location of the templates library, as per `core.NewSpec`.
+func (w *watcher) templatesDir() string {
+	md := &core.Metadata{CodegenDir: filepath.Join(w.c.Location, core.DomainDir)}
+	return abs(md.TemplatesDir())
+}
+
+func abs(path string) string {
+	if p, err := filepath.Abs(path); err == nil {
+		return p
+	}
+	return path
+}
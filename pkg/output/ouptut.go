@@ -9,6 +9,7 @@ import (
 	"github.com/maxzaleski/codegen/internal/lib/slice"
 	"github.com/maxzaleski/codegen/internal/slog"
 	"github.com/maxzaleski/codegen/pkg/gen/modules"
+	"github.com/pkg/errors"
 	"log"
 	"os"
 	"sort"
@@ -21,6 +22,20 @@ import (
 type (
 	Client interface {
 		PrintFinalReport(m modules.IMetrics)
+		// PrintPlanReport prints the outcome of each job as determined in plan mode.
+		PrintPlanReport(m modules.IMetrics)
+		// PrintCheckReport prints the files that are out of date (check mode), and returns their count.
+		PrintCheckReport(m modules.IMetrics) int
+		// PrintDiffReport prints the unified diff of each overwritten file, grouped by scope and package.
+		PrintDiffReport(m modules.IMetrics)
+		// PrintCleanReport prints the files removed (or, if `dryRun`, to be removed) in clean mode.
+		PrintCleanReport(m modules.IMetrics, dryRun bool)
+		// PrintSpec prints the scopes, jobs and packages of the given specification.
+		PrintSpec(spec *core.Spec)
+		// PrintValidReport prints a summary of the given (valid) specification.
+		PrintValidReport(spec *core.Spec)
+		// WritePatch writes the unified diff of each overwritten file to a patch file at `dest`.
+		WritePatch(m modules.IMetrics, dest string) error
 		PrintError(err error)
 		PrintInfo(lines ...string)
 	}
@@ -112,33 +127,9 @@ func (c *client) writeLog(err1 error) {
 }
 
 func (c *client) PrintFinalReport(ms modules.IMetrics) {
-	// Alphabetically sort the packages.
-	jm := ms.GetJobsMetrics()
-	scopes := slice.MapKeys(jm)
-	sort.Strings(scopes)
-
-	// Print metrics.go per package.
-	totalFiles, seenPkgsMap := 0, make(map[string]bool)
-	for _, s := range scopes {
-		printScope(s)
-
-		pms := jm[s].(map[string][]modules.MetricJob)
-		for pkg := range pms {
-			printPkg(pkg)
-
-			if len(pms[pkg]) != 0 && pkg != core.UniquePkgAlias {
-				if !seenPkgsMap[pkg] {
-					seenPkgsMap[pkg] = true
-				}
-			}
-			for _, mrt := range pms[pkg] {
-				printFile(mrt.FileAbsolutePath, mrt.FileCreated)
-				if mrt.FileCreated {
-					totalFiles++
-				}
-			}
-		}
-	}
+	// Print metrics per package.
+	totals, seenPkgs := printTree(ms, nil)
+	totalFiles := totals[modules.FileOutcomeCreated] + totals[modules.FileOutcomeOverwritten]
 
 	// Print final report.
 	if totalFiles == 0 {
@@ -151,12 +142,203 @@ func (c *client) PrintFinalReport(ms modules.IMetrics) {
 		log.Printf("\n%s Generated %s across %s in %s.\n",
 			eventPrefix("🤓"),
 			slog.Atom(slog.Blue, fmt.Sprintf("%d files", totalFiles)),
-			slog.Atom(slog.Blue, fmt.Sprintf("%d packages", len(slice.MapKeys(seenPkgsMap)))),
+			slog.Atom(slog.Blue, fmt.Sprintf("%d packages", seenPkgs)),
 			slog.Atom(slog.Cyan, time.Since(c.began).String()),
 		)
 	}
 }
 
+func (c *client) PrintPlanReport(ms modules.IMetrics) {
+	totals, _ := printTree(ms, nil)
+
+	log.Printf("\n%s Plan: %s, %s, %s.\n",
+		eventPrefix("🧐"),
+		slog.Atom(slog.Green, fmt.Sprintf("%d to create", totals[modules.FileOutcomeCreated])),
+		slog.Atom(slog.Yellow, fmt.Sprintf("%d to overwrite", totals[modules.FileOutcomeOverwritten])),
+		slog.Atom(slog.Grey, fmt.Sprintf("%d to skip", totals[modules.FileOutcomeIgnored])),
+	)
+	c.PrintInfo("Plan mode is enabled; nothing was written to disk.")
+}
+
+func (c *client) PrintCheckReport(ms modules.IMetrics) int {
+	totals, _ := printTree(ms, func(mrt modules.MetricJob) bool { return mrt.Outcome.IsDrifted() })
+	total := totals[modules.FileOutcomeMissing] + totals[modules.FileOutcomeModified] + totals[modules.FileOutcomeOrphaned]
+
+	if total == 0 {
+		log.Printf("\n%s Generated files are up to date.\n", eventPrefix("✅"))
+	} else {
+		log.Printf("\n%s %s out of date: %s, %s, %s.\n",
+			slog.Atom(slog.Red, eventPrefix("🚨")),
+			slog.Atom(slog.Blue, fmt.Sprintf("%d files", total)),
+			slog.Atom(slog.Red, fmt.Sprintf("%d missing", totals[modules.FileOutcomeMissing])),
+			slog.Atom(slog.Yellow, fmt.Sprintf("%d modified", totals[modules.FileOutcomeModified])),
+			slog.Atom(slog.Red, fmt.Sprintf("%d orphaned", totals[modules.FileOutcomeOrphaned])),
+		)
+		c.PrintInfo("Regenerate the files, or remove orphaned ones, then commit the result.")
+	}
+	if !ms.GetOrphanCheckMetrics() {
+		c.PrintInfo(
+			fmt.Sprintf("Orphaned files were not checked: no previous run is recorded in '%s/.run/diagnostics.db'.",
+				c.CodegenDir),
+			"Orphans are established against the files of the last run generated locally (e.g. not in a fresh checkout).",
+		)
+	}
+	return total
+}
+
+func (c *client) PrintDiffReport(ms modules.IMetrics) {
+	total := 0
+	walkTree(ms, hasDiff, printScope, printPkg, func(mrt modules.MetricJob) {
+		printFile(mrt.FileAbsolutePath, mrt.Outcome)
+		printDiff(mrt.Diff)
+		total++
+	})
+
+	if total == 0 {
+		log.Printf("\n%s %s\n", eventPrefix("💭"), "No differences with existing files.")
+	} else {
+		log.Printf("\n%s %s differ from existing files.\n",
+			eventPrefix("🔎"), slog.Atom(slog.Blue, fmt.Sprintf("%d files", total)))
+	}
+}
+
+func (c *client) PrintCleanReport(ms modules.IMetrics, dryRun bool) {
+	totals, _ := printTree(ms, func(mrt modules.MetricJob) bool { return mrt.Outcome == modules.FileOutcomeRemoved })
+	total, kept := totals[modules.FileOutcomeRemoved], 0
+	walkTree(ms, func(mrt modules.MetricJob) bool { return mrt.Outcome == modules.FileOutcomeIgnored },
+		nil, nil, func(modules.MetricJob) { kept++ })
+
+	files := slog.Atom(slog.Blue, fmt.Sprintf("%d files", total))
+	switch {
+	case total == 0:
+		log.Printf("\n%s %s\n", eventPrefix("💭"), "Nothing to remove.")
+	case dryRun:
+		log.Printf("\n%s Would remove %s.\n", eventPrefix("🧐"), files)
+	default:
+		log.Printf("\n%s Removed %s in %s.\n", eventPrefix("🧹"), files, slog.Atom(slog.Cyan, time.Since(c.began).String()))
+	}
+	if kept != 0 {
+		c.PrintInfo(fmt.Sprintf("%d files meant to be edited once created were kept; use -all to remove them.", kept))
+	}
+	if dryRun {
+		c.PrintInfo("Plan mode is enabled; nothing was removed from disk.")
+	}
+}
+
+func (c *client) PrintSpec(spec *core.Spec) {
+	for _, s := range spec.Config.Scopes() {
+		printScope(s.Key)
+		fmt.Printf("%s  %s\n", connectorTokenNeutral,
+			slog.Atom(slog.Grey, fmt.Sprintf("domain: %s, output: %s", s.ParentType, s.Output)))
+		for _, j := range s.Jobs {
+			printJob(j)
+		}
+	}
+
+	pkgs := append([]*core.Package{}, spec.Pkgs...)
+	sort.Slice(pkgs, func(i, j int) bool { return pkgs[i].Path < pkgs[j].Path })
+	fmt.Printf("\n📦 %s\n", slog.Atom(slog.Bold+slog.Cyan, "packages"))
+	for _, p := range pkgs {
+		fmt.Printf("%s  %s\n", connectorTokenNeutral, p.Path)
+		for _, e := range p.Endpoints {
+			fmt.Printf("%s     %s\n", connectorTokenNeutral,
+				slog.Atom(slog.Grey, fmt.Sprintf("%s %s (%s)", e.Method, e.Path, e.Name)))
+		}
+	}
+
+	log.Printf("\n%s %s\n", eventPrefix("📋"), specSummary(spec))
+}
+
+func (c *client) PrintValidReport(spec *core.Spec) {
+	log.Printf("\n%s Specification is valid: %s\n", eventPrefix("✅"), specSummary(spec))
+}
+
+// specSummary returns the number of scopes, jobs and packages of the given specification.
+func specSummary(spec *core.Spec) string {
+	scopes, jobs := spec.Config.Scopes(), 0
+	for _, s := range scopes {
+		jobs += len(s.Jobs)
+	}
+	return fmt.Sprintf("%s, %s, %s.",
+		slog.Atom(slog.Blue, fmt.Sprintf("%d scopes", len(scopes))),
+		slog.Atom(slog.Blue, fmt.Sprintf("%d jobs", jobs)),
+		slog.Atom(slog.Blue, fmt.Sprintf("%d packages", len(spec.Pkgs))),
+	)
+}
+
+func (c *client) WritePatch(ms modules.IMetrics, dest string) error {
+	var sb strings.Builder
+	walkTree(ms, hasDiff, nil, nil, func(mrt modules.MetricJob) { sb.WriteString(mrt.Diff) })
+
+	if err := os.WriteFile(dest, []byte(sb.String()), 0644); err != nil {
+		return errors.Wrapf(err, "failed to write patch file at '%s'", dest)
+	}
+	c.PrintInfo(fmt.Sprintf("Patch file written to %s.", slog.Atom(slog.Cyan, dest)))
+	return nil
+}
+
+func hasDiff(mrt modules.MetricJob) bool {
+	return mrt.Diff != ""
+}
+
+// walkTree walks the captured jobs in alphabetical order (scope > package > file), calling the given functions for
+// each job matching the filter, and their parent scope and package.
+//
+// A nil filter matches all jobs; nil functions are ignored.
+func walkTree(
+	ms modules.IMetrics, filter func(modules.MetricJob) bool, onScope, onPkg func(string), onJob func(modules.MetricJob),
+) {
+	if filter == nil {
+		filter = func(modules.MetricJob) bool { return true }
+	}
+
+	jm := ms.GetJobsMetrics()
+	scopes := slice.MapKeys(jm)
+	sort.Strings(scopes)
+
+	for _, s := range scopes {
+		pms := jm[s].(map[string][]modules.MetricJob)
+		pkgs := slice.Filter(slice.MapKeys(pms), func(pkg string) bool {
+			return len(slice.Filter(pms[pkg], filter)) != 0
+		})
+		if len(pkgs) == 0 {
+			continue
+		}
+		sort.Strings(pkgs)
+
+		if onScope != nil {
+			onScope(s)
+		}
+		for _, pkg := range pkgs {
+			if onPkg != nil {
+				onPkg(pkg)
+			}
+			for _, mrt := range slice.Filter(pms[pkg], filter) {
+				onJob(mrt)
+			}
+		}
+	}
+}
+
+// printTree prints the captured jobs matching the filter as a tree (scope > package > file), and returns the number
+// of files per outcome alongside the number of packages affected.
+func printTree(ms modules.IMetrics, filter func(modules.MetricJob) bool) (map[modules.FileOutcome]int, int) {
+	totals, seenPkgsMap := make(map[modules.FileOutcome]int), make(map[string]bool)
+
+	pkg := ""
+	walkTree(ms, filter, printScope, func(p string) {
+		pkg = p
+		printPkg(p)
+	}, func(mrt modules.MetricJob) {
+		printFile(mrt.FileAbsolutePath, mrt.Outcome)
+		totals[mrt.Outcome]++
+		if mrt.FileCreated && pkg != core.UniquePkgAlias {
+			seenPkgsMap[pkg] = true
+		}
+	})
+	return totals, len(seenPkgsMap)
+}
+
 func (c *client) getLogDest() string {
 	return c.Cwd + "/codegen_error.log"
 }
@@ -169,14 +351,67 @@ func printPkg(name string) {
 	fmt.Printf("%s\n%s 📦 %s\n", connectorTokenNeutral, connectorToken, slog.Atom(slog.Bold+slog.Cyan, name+"/"))
 }
 
-func printFile(name string, created bool) {
+func printJob(j *core.ScopeJob) {
+	mode := "create once"
+	switch {
+	case j.Override:
+		mode = "override"
+	case len(j.OverrideOn) != 0:
+		keys := slice.MapKeys(j.OverrideOn)
+		sort.Strings(keys)
+		mode = "override-on: " + strings.Join(keys, ", ")
+	}
+	if j.Unique {
+		mode += ", unique"
+	}
+	tts := slice.Map(j.Templates, func(t core.ScopeJobTemplate) string { return t.Name })
+
+	fmt.Printf("%s\n%s ⚙️  %s %s %s\n", connectorTokenNeutral, connectorToken,
+		slog.Atom(slog.Bold, j.Key), slog.Atom(slog.Grey, "→"), j.FileName)
+	fmt.Printf("%s     %s\n", connectorTokenNeutral, slog.Atom(slog.Grey, mode+"; templates: "+strings.Join(tts, ", ")))
+}
+
+func printFile(name string, outcome modules.FileOutcome) {
 	statusToken, statusColour := fileIgnoredToken, slog.Grey
 	fileColour := statusColour
-	if created {
+	switch outcome {
+	case modules.FileOutcomeCreated:
 		statusToken, statusColour = fileCreatedToken, slog.Green
+	case modules.FileOutcomeOverwritten, modules.FileOutcomeModified:
+		statusToken, statusColour = fileOverwrittenToken, slog.Yellow
+	case modules.FileOutcomeMissing:
+		statusToken, statusColour = fileMissingToken, slog.Red
+	case modules.FileOutcomeOrphaned:
+		statusToken, statusColour = fileOrphanedToken, slog.Red
+	case modules.FileOutcomeRemoved:
+		statusToken, statusColour = fileRemovedToken, slog.Red
+	}
+	if statusColour != slog.Grey {
 		fileColour = slog.White
 	}
-	fmt.Printf("%s  %s  %s\n", connectorTokenNeutral, slog.Atom(statusColour, statusToken), slog.Atom(fileColour, name))
+	suffix := ""
+	if outcome.IsDrifted() {
+		suffix = " " + slog.Atom(slog.Grey, "("+string(outcome)+")")
+	}
+	fmt.Printf("%s  %s  %s%s\n",
+		connectorTokenNeutral, slog.Atom(statusColour, statusToken), slog.Atom(fileColour, name), suffix)
+}
+
+func printDiff(d string) {
+	for _, line := range strings.Split(strings.TrimSuffix(d, "\n"), "\n") {
+		colour := slog.Grey
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			colour = slog.Bold
+		case strings.HasPrefix(line, "@@"):
+			colour = slog.Cyan
+		case strings.HasPrefix(line, "+"):
+			colour = slog.Green
+		case strings.HasPrefix(line, "-"):
+			colour = slog.Red
+		}
+		fmt.Printf("%s     %s\n", connectorTokenNeutral, slog.Atom(colour, line))
+	}
 }
 
 func eventPrefix(emoji string) string {
@@ -2,6 +2,7 @@ package output
 
 import (
 	"github.com/maxzaleski/codegen/internal/core"
+	"github.com/maxzaleski/codegen/pkg/gen/modules"
 	"os"
 	"testing"
 	"time"
@@ -11,14 +12,10 @@ import (
 
 func TestOutput(t *testing.T) {
 	// t.Skip("Visual inspection only")
-	cwd, err := os.Getwd()
-	if err != nil {
-		t.Error(cwd)
-	}
 	var o = &client{
 		began: time.Now(),
 		Metadata: core.Metadata{
-			Cwd: cwd,
+			Cwd: t.TempDir(), // -> The error log is written to the working directory.
 		},
 	}
 
@@ -31,11 +28,23 @@ func TestOutput(t *testing.T) {
 	})
 
 	t.Run("file created", func(t *testing.T) {
-		printFile("Name", true)
+		printFile("Name", modules.FileOutcomeCreated)
+	})
+
+	t.Run("file overwritten", func(t *testing.T) {
+		printFile("Name", modules.FileOutcomeOverwritten)
+	})
+
+	t.Run("file missing", func(t *testing.T) {
+		printFile("Name", modules.FileOutcomeMissing)
 	})
 
 	t.Run("file ignored", func(t *testing.T) {
-		printFile("Name", false)
+		printFile("Name", modules.FileOutcomeIgnored)
+	})
+
+	t.Run("diff", func(t *testing.T) {
+		printDiff("--- a/Name\n+++ b/Name\n@@ -1 +1 @@\n-foo\n+bar\n")
 	})
 
 	t.Run("info", func(t *testing.T) {
@@ -44,6 +53,9 @@ func TestOutput(t *testing.T) {
 
 	t.Run("error", func(t *testing.T) {
 		o.PrintError(errors.WithStack(errors.New("this is an error")))
+		if _, err := os.Stat(o.getLogDest()); err != nil {
+			t.Error(err)
+		}
 	})
 
 	//t.Verify("final reporting", func(t *testing.T) {
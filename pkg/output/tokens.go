@@ -3,6 +3,10 @@ package output
 const (
 	fileCreatedToken      = "+"
 	fileIgnoredToken      = "|"
+	fileOverwrittenToken  = "~"
+	fileMissingToken      = "!"
+	fileOrphanedToken     = "-"
+	fileRemovedToken      = "-"
 	eventToken            = "➤"
 	connectorTokenFile    = "   |\n"
 	connectorToken        = "├─"
@@ -0,0 +1,138 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+type (
+	// Archive is an `FS` emitting the written files as a single tar or zip archive.
+	//
+	// Files are held in memory until `Close` is called, at which point they are written to the archive in
+	// alphabetical order; it starts empty, regardless of the contents of the disk.
+	Archive struct {
+		*Memory
+
+		w      io.Writer
+		root   string
+		format ArchiveFormat
+	}
+
+	// ArchiveFormat represents the format of an `Archive`.
+	ArchiveFormat string
+)
+
+const (
+	ArchiveFormatTar   ArchiveFormat = "tar"
+	ArchiveFormatTarGz ArchiveFormat = "tar.gz"
+	ArchiveFormatZip   ArchiveFormat = "zip"
+)
+
+var _ FS = (*Archive)(nil)
+
+// NewArchive returns a new `Archive` writing to `w` in the given format; entries are named relative to `root`.
+func NewArchive(w io.Writer, format ArchiveFormat, root string) *Archive {
+	return &Archive{
+		Memory: NewMemory(),
+		w:      w,
+		root:   root,
+		format: format,
+	}
+}
+
+// ArchiveFormatOf returns the archive format matching the extension of `path`, if any.
+func ArchiveFormatOf(path string) (ArchiveFormat, bool) {
+	switch {
+	case strings.HasSuffix(path, ".tar.gz"), strings.HasSuffix(path, ".tgz"):
+		return ArchiveFormatTarGz, true
+	case strings.HasSuffix(path, ".tar"):
+		return ArchiveFormatTar, true
+	case strings.HasSuffix(path, ".zip"):
+		return ArchiveFormatZip, true
+	default:
+		return "", false
+	}
+}
+
+// Close writes the files to the archive; the underlying writer is not closed.
+func (a *Archive) Close() error {
+	switch a.format {
+	case ArchiveFormatTar:
+		return a.writeTar(a.w)
+	case ArchiveFormatTarGz:
+		gw := gzip.NewWriter(a.w)
+		if err := a.writeTar(gw); err != nil {
+			return err
+		}
+		return errors.Wrap(gw.Close(), "failed to close gzip writer")
+	case ArchiveFormatZip:
+		return a.writeZip()
+	default:
+		return errors.Errorf("unknown archive format '%s'", a.format)
+	}
+}
+
+func (a *Archive) writeTar(w io.Writer) error {
+	tw := tar.NewWriter(w)
+	err := a.walk(func(name string, fi memFileInfo) error {
+		hdr := &tar.Header{
+			Name:    name,
+			Mode:    int64(fi.Mode()),
+			Size:    fi.Size(),
+			ModTime: fi.ModTime(),
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		_, err := tw.Write(fi.b)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to write tar archive")
+	}
+	return errors.Wrap(tw.Close(), "failed to close tar archive")
+}
+
+func (a *Archive) writeZip() error {
+	zw := zip.NewWriter(a.w)
+	err := a.walk(func(name string, fi memFileInfo) error {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     name,
+			Method:   zip.Deflate,
+			Modified: fi.ModTime(),
+		})
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(fi.b)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to write zip archive")
+	}
+	return errors.Wrap(zw.Close(), "failed to close zip archive")
+}
+
+// walk calls `fn` for each file in alphabetical order, alongside its entry name.
+func (a *Archive) walk(fn func(name string, fi memFileInfo) error) error {
+	for _, path := range a.Paths() {
+		name, err := filepath.Rel(a.root, path)
+		if err != nil || strings.HasPrefix(name, "..") {
+			return errors.Errorf("file '%s' is outside of the archive root '%s'", path, a.root)
+		}
+		fi, err := a.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err = fn(filepath.ToSlash(name), fi.(memFileInfo)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+package vfs
+
+import (
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+type (
+	// Memory is an in-memory `FS`, e.g. for tests and dry runs.
+	//
+	// Directories are implicit: they exist as long as they contain a file.
+	Memory struct {
+		mu    *sync.RWMutex
+		files map[string]*memFile
+	}
+
+	memFile struct {
+		b       []byte
+		modTime time.Time
+	}
+
+	memFileInfo struct {
+		name string
+		*memFile
+	}
+)
+
+var _ FS = (*Memory)(nil)
+
+// NewMemory returns a new, empty `Memory` filesystem.
+func NewMemory() *Memory {
+	return &Memory{
+		mu:    &sync.RWMutex{},
+		files: make(map[string]*memFile),
+	}
+}
+
+func (m *Memory) Stat(path string) (fs.FileInfo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "stat", Path: path, Err: fs.ErrNotExist}
+	}
+	return memFileInfo{name: filepath.Base(path), memFile: f}, nil
+}
+
+func (m *Memory) ReadFile(path string) ([]byte, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	f, ok := m.files[filepath.Clean(path)]
+	if !ok {
+		return nil, &fs.PathError{Op: "open", Path: path, Err: fs.ErrNotExist}
+	}
+	return append([]byte(nil), f.b...), nil
+}
+
+func (m *Memory) WriteFile(path string, b []byte) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[filepath.Clean(path)] = &memFile{b: append([]byte(nil), b...), modTime: time.Now()}
+	return nil
+}
+
+func (m *Memory) Remove(path string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	path = filepath.Clean(path)
+	if _, ok := m.files[path]; !ok {
+		return &fs.PathError{Op: "remove", Path: path, Err: fs.ErrNotExist}
+	}
+	delete(m.files, path)
+	return nil
+}
+
+// Paths returns the paths of the files, in alphabetical order.
+func (m *Memory) Paths() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	paths := make([]string, 0, len(m.files))
+	for path := range m.files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+func (fi memFileInfo) Name() string       { return fi.name }
+func (fi memFileInfo) Size() int64        { return int64(len(fi.b)) }
+func (fi memFileInfo) Mode() fs.FileMode  { return 0644 }
+func (fi memFileInfo) ModTime() time.Time { return fi.modTime }
+func (fi memFileInfo) IsDir() bool        { return false }
+func (fi memFileInfo) Sys() any           { return nil }
@@ -0,0 +1,56 @@
+// Package vfs defines the filesystem generated files are written to.
+package vfs
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	ifs "github.com/maxzaleski/codegen/internal/fs"
+)
+
+// FS represents the filesystem generated files are written to; paths are absolute.
+//
+// Implementations must be safe for concurrent use.
+type FS interface {
+	// Stat returns the information of the file at `path`; the error satisfies `os.IsNotExist` if absent.
+	Stat(path string) (fs.FileInfo, error)
+	// ReadFile returns the contents of the file at `path`.
+	ReadFile(path string) ([]byte, error)
+	// WriteFile writes `b` to the file at `path`, creating its directory if necessary.
+	WriteFile(path string, b []byte) error
+	// Remove removes the file, or empty directory, at `path`.
+	Remove(path string) error
+}
+
+type osFS struct{}
+
+// OS returns the `FS` backed by the real disk.
+func OS() FS {
+	return osFS{}
+}
+
+// IsOS returns true if `fsys` is backed by the real disk.
+func IsOS(fsys FS) bool {
+	_, ok := fsys.(osFS)
+	return ok
+}
+
+func (osFS) Stat(path string) (fs.FileInfo, error) {
+	return os.Stat(path)
+}
+
+func (osFS) ReadFile(path string) ([]byte, error) {
+	return os.ReadFile(path)
+}
+
+func (osFS) WriteFile(path string, b []byte) error {
+	if _, err := ifs.CreateDirINE(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return ifs.CreateFile(path, b)
+}
+
+func (osFS) Remove(path string) error {
+	return os.Remove(path)
+}
@@ -0,0 +1,140 @@
+package vfs
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-playground/assert"
+)
+
+func TestFS(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys FS
+		dir  string
+	}{
+		{name: "os", fsys: OS(), dir: t.TempDir()},
+		{name: "memory", fsys: NewMemory(), dir: "/root"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(tt.dir, "a/b/file.go")
+
+			_, err := tt.fsys.Stat(path)
+			assert.Equal(t, os.IsNotExist(err), true)
+
+			// Parent directories are created as necessary.
+			assert.Equal(t, tt.fsys.WriteFile(path, []byte("package b")), nil)
+			fi, err := tt.fsys.Stat(path)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, fi.Name(), "file.go")
+			assert.Equal(t, fi.Size(), int64(9))
+
+			b, err := tt.fsys.ReadFile(path)
+			assert.Equal(t, err, nil)
+			assert.Equal(t, string(b), "package b")
+
+			assert.Equal(t, tt.fsys.Remove(path), nil)
+			_, err = tt.fsys.ReadFile(path)
+			assert.Equal(t, os.IsNotExist(err), true)
+		})
+	}
+}
+
+func TestArchive(t *testing.T) {
+	files := map[string]string{
+		"/root/b/file.go": "package b",
+		"/root/a.go":      "package a",
+	}
+	expected := []string{"a.go", "b/file.go"}
+
+	tests := []struct {
+		format ArchiveFormat
+		read   func(t *testing.T, b []byte) map[string]string
+	}{
+		{format: ArchiveFormatTar, read: readTar},
+		{format: ArchiveFormatZip, read: readZip},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			var buf bytes.Buffer
+			a := NewArchive(&buf, tt.format, "/root")
+			for path, s := range files {
+				assert.Equal(t, a.WriteFile(path, []byte(s)), nil)
+			}
+			assert.Equal(t, a.Close(), nil)
+
+			entries := tt.read(t, buf.Bytes())
+			assert.Equal(t, len(entries), len(expected))
+			for _, name := range expected {
+				assert.Equal(t, entries[name], files["/root/"+name])
+			}
+		})
+	}
+
+	t.Run("outside of root", func(t *testing.T) {
+		a := NewArchive(io.Discard, ArchiveFormatTar, "/root")
+		assert.Equal(t, a.WriteFile("/elsewhere/a.go", nil), nil)
+		assert.NotEqual(t, a.Close(), nil)
+	})
+}
+
+func TestArchiveFormatOf(t *testing.T) {
+	tests := []struct {
+		path     string
+		expected ArchiveFormat
+		ok       bool
+	}{
+		{path: "out.tar", expected: ArchiveFormatTar, ok: true},
+		{path: "out.tar.gz", expected: ArchiveFormatTarGz, ok: true},
+		{path: "out.tgz", expected: ArchiveFormatTarGz, ok: true},
+		{path: "out.zip", expected: ArchiveFormatZip, ok: true},
+		{path: "out.rar"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			format, ok := ArchiveFormatOf(tt.path)
+			assert.Equal(t, format, tt.expected)
+			assert.Equal(t, ok, tt.ok)
+		})
+	}
+}
+
+func readTar(t *testing.T, b []byte) map[string]string {
+	entries := make(map[string]string)
+	tr := tar.NewReader(bytes.NewReader(b))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		eb, _ := io.ReadAll(tr)
+		entries[hdr.Name] = string(eb)
+	}
+}
+
+func readZip(t *testing.T, b []byte) map[string]string {
+	entries := make(map[string]string)
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		if err != nil {
+			t.Fatal(err)
+		}
+		eb, _ := io.ReadAll(rc)
+		_ = rc.Close()
+		entries[f.Name] = string(eb)
+	}
+	return entries
+}